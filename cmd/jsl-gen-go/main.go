@@ -0,0 +1,73 @@
+// Command jsl-gen-go generates Go types from a JSON Schema Language schema.
+//
+// Usage:
+//
+//	jsl-gen-go [-package name] [-root name] [-o file] schema.json
+//
+// The generated code is written to the file given by -o, or to standard output
+// if there is none. See package gogen for how schemas are turned into Go types.
+//
+// jsl-gen-go is meant to be used with go generate:
+//
+//	//go:generate jsl-gen-go -root User -o user.go user.jsl.json
+//
+// When run by go generate, the package of the generated code defaults to the
+// package containing the directive.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	jsl "github.com/json-schema-language/json-schema-language-go"
+	"github.com/json-schema-language/json-schema-language-go/gogen"
+)
+
+func main() {
+	pkg := flag.String("package", os.Getenv("GOPACKAGE"), "name of the package of the generated code")
+	root := flag.String("root", "Root", "name of the type for the root schema")
+	out := flag.String("o", "", "file to write the generated code to, instead of standard output")
+
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: jsl-gen-go [flags] schema.json\n\n")
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(flag.Arg(0), *pkg, *root, *out); err != nil {
+		fmt.Fprintf(os.Stderr, "jsl-gen-go: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(schemaPath, pkg, root, out string) error {
+	data, err := ioutil.ReadFile(schemaPath)
+	if err != nil {
+		return err
+	}
+
+	schema, err := jsl.ParseSchema(data)
+	if err != nil {
+		return fmt.Errorf("%s: %v", schemaPath, err)
+	}
+
+	g := gogen.Generator{Package: pkg, RootName: root, Command: "jsl-gen-go"}
+	src, err := g.Generate(schema)
+	if err != nil {
+		return fmt.Errorf("%s: %v", schemaPath, err)
+	}
+
+	if out == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+
+	return ioutil.WriteFile(out, src, 0644)
+}
@@ -0,0 +1,67 @@
+// Command jsl-gen-ts generates TypeScript declarations from a JSON Schema
+// Language schema.
+//
+// Usage:
+//
+//	jsl-gen-ts [-root name] [-o file] schema.json
+//
+// The generated code is written to the file given by -o, or to standard output
+// if there is none. See package tsgen for how schemas are turned into
+// TypeScript types.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	jsl "github.com/json-schema-language/json-schema-language-go"
+	"github.com/json-schema-language/json-schema-language-go/tsgen"
+)
+
+func main() {
+	root := flag.String("root", "Root", "name of the type for the root schema")
+	out := flag.String("o", "", "file to write the generated code to, instead of standard output")
+
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: jsl-gen-ts [flags] schema.json\n\n")
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(flag.Arg(0), *root, *out); err != nil {
+		fmt.Fprintf(os.Stderr, "jsl-gen-ts: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(schemaPath, root, out string) error {
+	data, err := ioutil.ReadFile(schemaPath)
+	if err != nil {
+		return err
+	}
+
+	schema, err := jsl.ParseSchema(data)
+	if err != nil {
+		return fmt.Errorf("%s: %v", schemaPath, err)
+	}
+
+	g := tsgen.Generator{RootName: root, Command: "jsl-gen-ts"}
+	src, err := g.Generate(schema)
+	if err != nil {
+		return fmt.Errorf("%s: %v", schemaPath, err)
+	}
+
+	if out == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+
+	return ioutil.WriteFile(out, src, 0644)
+}
@@ -0,0 +1,372 @@
+// Command jsl validates JSON documents against JSON Schema Language schemas, and
+// checks that schemas are correct.
+//
+// Usage:
+//
+//	jsl validate [-strict] [-max-errors n] [-max-depth n] -schema schema.json file...
+//	jsl validate -ndjson [-workers n] [-valid-out file] [-invalid-out file] [flags] -schema schema.json file...
+//	jsl verify schema.json...
+//
+// validate prints the problems with each file that is not valid against the
+// schema, one per line. verify prints the problems with each schema that is not
+// correct. A file name of - stands for standard input. Flags may also be given
+// with two dashes, such as --strict.
+//
+// With -ndjson, each line of each file is validated separately, across several
+// goroutines, and the JSON Pointer in each problem starts with the number of
+// the line. A summary of how many lines were valid is printed to standard
+// error for each file. Lines that are not JSON count as not valid. Lines that
+// are valid and lines that are not can be written to the files given by
+// -valid-out and -invalid-out.
+//
+// The exit code tells apart the different ways jsl can fail:
+//
+//	0  every file is valid, or every schema is correct
+//	1  some file is not valid against the schema, or is not JSON
+//	2  jsl was used incorrectly
+//	3  some schema is not correct
+//	4  some file or schema could not be read, or some output could not be written
+//	5  some file is nested more deeply than -max-depth allows
+//
+// If there are several kinds of failure, the exit code is the greatest of
+// them.
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	jsl "github.com/json-schema-language/json-schema-language-go"
+)
+
+const (
+	exitInvalid = 1
+	exitUsage   = 2
+	exitSchema  = 3
+	exitIO      = 4
+	exitDepth   = 5
+)
+
+const usage = `usage:
+  jsl validate [flags] -schema schema.json file...
+  jsl verify schema.json...
+
+Run jsl validate -h for the flags of validate.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(exitUsage)
+	}
+
+	var code int
+	switch os.Args[1] {
+	case "validate":
+		code = validate(os.Args[2:])
+	case "verify":
+		code = verify(os.Args[2:])
+	case "-h", "-help", "--help", "help":
+		fmt.Fprint(os.Stdout, usage)
+	default:
+		fmt.Fprintf(os.Stderr, "jsl: unknown command %q\n\n%s", os.Args[1], usage)
+		code = exitUsage
+	}
+
+	os.Exit(code)
+}
+
+func validate(args []string) int {
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	schemaPath := flags.String("schema", "", "schema to validate against (required)")
+	strict := flags.Bool("strict", false, "reject properties the schema does not mention")
+	maxErrors := flags.Int("max-errors", 0, "maximum number of errors to report per file, or 0 for all")
+	maxDepth := flags.Int("max-depth", 0, `maximum number of nested "ref"s to follow, or 0 for no maximum`)
+	ndjson := flags.Bool("ndjson", false, "validate each line of the files separately")
+	workers := flags.Int("workers", 0, "number of lines to validate at once with -ndjson, or 0 for one per CPU")
+	validOut := flags.String("valid-out", "", "file to write valid lines to with -ndjson")
+	invalidOut := flags.String("invalid-out", "", "file to write invalid lines to with -ndjson")
+
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: jsl validate [flags] -schema schema.json file...\n\n")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+
+		return exitUsage
+	}
+
+	if *schemaPath == "" || flags.NArg() == 0 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	if !*ndjson && (*validOut != "" || *invalidOut != "") {
+		fmt.Fprintf(os.Stderr, "jsl: -valid-out and -invalid-out require -ndjson\n")
+		return exitUsage
+	}
+
+	schema, code := readSchema(*schemaPath)
+	if code != 0 {
+		return code
+	}
+
+	if code := printSchemaErrors(*schemaPath, schema); code != 0 {
+		return code
+	}
+
+	compiled, err := jsl.Compile(schema)
+	if err != nil {
+		fmt.Printf("%s: %v\n", *schemaPath, err)
+		return exitSchema
+	}
+
+	v := jsl.Validator{
+		MaxErrors:               *maxErrors,
+		MaxDepth:                *maxDepth,
+		StrictInstanceSemantics: *strict,
+	}
+
+	if *ndjson {
+		n := jsl.NDJSONValidator{Validator: v, Workers: *workers}
+		return validateLines(&n, compiled, flags.Args(), *validOut, *invalidOut)
+	}
+
+	code = 0
+	for _, path := range flags.Args() {
+		data, err := readFile(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "jsl: %v\n", err)
+			code = worst(code, exitIO)
+			continue
+		}
+
+		result, err := v.ValidateCompiledBytes(compiled, data)
+		if errors.Is(err, jsl.ErrMaxDepthExceeded) {
+			// Whether the file is valid is not known, so this is not reported
+			// as a problem with the file.
+			fmt.Fprintf(os.Stderr, "jsl: %s: %v\n", path, err)
+			code = worst(code, exitDepth)
+			continue
+		} else if err != nil {
+			// The file is not JSON.
+			fmt.Printf("%s: %v\n", path, err)
+			code = worst(code, exitInvalid)
+			continue
+		}
+
+		for _, message := range result.Messages(nil) {
+			fmt.Printf("%s: %s\n", path, message)
+		}
+
+		if !result.IsValid() {
+			code = worst(code, exitInvalid)
+		}
+	}
+
+	return code
+}
+
+// validateLines validates the lines of each file with n, writing the lines to
+// the files named by validOut and invalidOut, if any.
+func validateLines(n *jsl.NDJSONValidator, schema *jsl.CompiledSchema, paths []string, validOut, invalidOut string) (code int) {
+	var outputs []*outputFile
+	defer func() {
+		// Lines written to an output are only known to be there once it is
+		// flushed and closed without error.
+		for _, out := range outputs {
+			if err := out.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "jsl: %v\n", err)
+				code = worst(code, exitIO)
+			}
+		}
+	}()
+
+	for _, out := range []struct {
+		path string
+		w    *io.Writer
+	}{
+		{validOut, &n.Valid},
+		{invalidOut, &n.Invalid},
+	} {
+		if out.path == "" {
+			continue
+		}
+
+		f, err := os.Create(out.path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "jsl: %v\n", err)
+			return exitIO
+		}
+
+		output := &outputFile{Writer: bufio.NewWriter(f), f: f}
+		outputs = append(outputs, output)
+		*out.w = output
+	}
+
+	for _, path := range paths {
+		r, err := openFile(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "jsl: %v\n", err)
+			code = worst(code, exitIO)
+			continue
+		}
+
+		n.Errors = func(errs []jsl.ValidationError) error {
+			for _, err := range errs {
+				fmt.Printf("%s: %s\n", path, err.Message(nil))
+			}
+
+			return nil
+		}
+
+		n.Malformed = func(err *jsl.LineError) error {
+			fmt.Printf("%s: %v\n", path, err)
+			return nil
+		}
+
+		summary, err := n.Validate(schema, r)
+		r.Close()
+
+		fmt.Fprintf(os.Stderr, "%s: %d valid, %d invalid\n", path, summary.Valid, summary.Invalid)
+		if summary.Invalid > 0 {
+			code = worst(code, exitInvalid)
+		}
+
+		if errors.Is(err, jsl.ErrMaxDepthExceeded) {
+			fmt.Fprintf(os.Stderr, "jsl: %s: %v\n", path, err)
+			code = worst(code, exitDepth)
+		} else if err != nil {
+			fmt.Fprintf(os.Stderr, "jsl: %s: %v\n", path, err)
+			code = worst(code, exitIO)
+		}
+	}
+
+	return code
+}
+
+// outputFile is a file that -valid-out or -invalid-out writes lines to.
+type outputFile struct {
+	*bufio.Writer
+	f *os.File
+}
+
+// Close flushes and closes the file, and returns the first error in doing so.
+func (o *outputFile) Close() error {
+	err := o.Flush()
+	if closeErr := o.f.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+func verify(args []string) int {
+	flags := flag.NewFlagSet("verify", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: jsl verify schema.json...\n")
+	}
+
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+
+		return exitUsage
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	code := 0
+	for _, path := range flags.Args() {
+		schema, schemaCode := readSchema(path)
+		if schemaCode != 0 {
+			code = worst(code, schemaCode)
+			continue
+		}
+
+		code = worst(code, printSchemaErrors(path, schema))
+	}
+
+	return code
+}
+
+// readSchema reads and parses a schema, without verifying it. It prints any
+// problem with doing so, and returns the corresponding exit code.
+func readSchema(path string) (jsl.Schema, int) {
+	data, err := readFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "jsl: %v\n", err)
+		return jsl.Schema{}, exitIO
+	}
+
+	schema, err := jsl.ParseSchema(data)
+	if err != nil {
+		fmt.Printf("%s: %v\n", path, err)
+		return jsl.Schema{}, exitSchema
+	}
+
+	return schema, 0
+}
+
+// printSchemaErrors prints the problems with a schema that is not correct, and
+// returns the corresponding exit code.
+func printSchemaErrors(path string, schema jsl.Schema) int {
+	errs := schema.VerifyAll()
+	for _, err := range errs {
+		fmt.Printf("%s: %v\n", path, err)
+	}
+
+	if len(errs) > 0 {
+		return exitSchema
+	}
+
+	return 0
+}
+
+// readFile reads the named file, or standard input if path is "-".
+func readFile(path string) ([]byte, error) {
+	r, err := openFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer r.Close()
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil && path == "-" {
+		return nil, fmt.Errorf("reading standard input: %v", err)
+	}
+
+	return data, err
+}
+
+// openFile opens the named file, or standard input if path is "-".
+func openFile(path string) (io.ReadCloser, error) {
+	if path == "-" {
+		return ioutil.NopCloser(os.Stdin), nil
+	}
+
+	return os.Open(path)
+}
+
+// worst returns the greater of two exit codes.
+func worst(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
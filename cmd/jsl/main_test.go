@@ -0,0 +1,246 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	type testCase struct {
+		name string
+		args []string
+		code int
+		out  string
+	}
+
+	testCases := []testCase{
+		{
+			"valid",
+			[]string{"-schema", "user.json", "valid.json"},
+			0,
+			"",
+		},
+		{
+			"not valid",
+			[]string{"-schema", "user.json", "valid.json", "invalid.json"},
+			exitInvalid,
+			"invalid.json: /name: expected string, got number\n",
+		},
+		{
+			"not JSON",
+			[]string{"-schema", "user.json", "malformed.json"},
+			exitInvalid,
+			"malformed.json: unexpected end of JSON input\n",
+		},
+		{
+			"no schema",
+			[]string{"valid.json"},
+			exitUsage,
+			"",
+		},
+		{
+			"valid-out without ndjson",
+			[]string{"-schema", "user.json", "-valid-out", "out.jsonl", "valid.json"},
+			exitUsage,
+			"",
+		},
+		{
+			"incorrect schema",
+			[]string{"-schema", "incorrect.json", "valid.json"},
+			exitSchema,
+			"incorrect.json: jsl: no such definition: a (at \"/ref\")\n",
+		},
+		{
+			"missing file",
+			[]string{"-schema", "user.json", "invalid.json", "missing.json"},
+			exitIO,
+			"invalid.json: /name: expected string, got number\n",
+		},
+		{
+			"max depth",
+			[]string{"-schema", "list.json", "-max-depth", "2", "deep.json", "invalid.json"},
+			exitDepth,
+			"invalid.json: expected array, got object\n",
+		},
+		{
+			"ndjson",
+			[]string{"-ndjson", "-schema", "user.json", "users.jsonl"},
+			exitInvalid,
+			"users.jsonl: /2/name: expected string, got number\nusers.jsonl: line 3: unexpected end of JSON input\n",
+		},
+		{
+			"ndjson max depth",
+			[]string{"-ndjson", "-schema", "list.json", "-max-depth", "2", "deep.json"},
+			exitDepth,
+			"",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := testFiles(t)
+
+			var code int
+			out := captureStdout(t, func() {
+				code = validate(inDir(dir, tt.args))
+			})
+
+			assert.Equal(t, tt.code, code)
+			assert.Equal(t, tt.out, strings.ReplaceAll(out, dir+string(filepath.Separator), ""))
+		})
+	}
+}
+
+func TestValidateOutputs(t *testing.T) {
+	dir := testFiles(t)
+	validOut := filepath.Join(dir, "valid.jsonl")
+	invalidOut := filepath.Join(dir, "invalid.jsonl")
+
+	var code int
+	captureStdout(t, func() {
+		code = validate([]string{
+			"-ndjson", "-workers", "2",
+			"-valid-out", validOut,
+			"-invalid-out", invalidOut,
+			"-schema", filepath.Join(dir, "user.json"),
+			filepath.Join(dir, "users.jsonl"),
+		})
+	})
+
+	assert.Equal(t, exitInvalid, code)
+
+	valid, err := ioutil.ReadFile(validOut)
+	assert.NoError(t, err)
+	assert.Equal(t, "{\"name\":\"a\"}\n{\"name\":\"d\"}\n", string(valid))
+
+	invalid, err := ioutil.ReadFile(invalidOut)
+	assert.NoError(t, err)
+	assert.Equal(t, "{\"name\":2}\n{\"name\":\n", string(invalid))
+}
+
+func TestValidateOutputError(t *testing.T) {
+	// Writes to /dev/full always fail, as if the disk were full.
+	if _, err := os.Stat("/dev/full"); err != nil {
+		t.Skip("no /dev/full")
+	}
+
+	dir := testFiles(t)
+
+	var code int
+	captureStdout(t, func() {
+		code = validate([]string{
+			"-ndjson",
+			"-valid-out", "/dev/full",
+			"-schema", filepath.Join(dir, "user.json"),
+			filepath.Join(dir, "valid.json"),
+		})
+	})
+
+	assert.Equal(t, exitIO, code)
+}
+
+func TestVerify(t *testing.T) {
+	type testCase struct {
+		name string
+		args []string
+		code int
+		out  string
+	}
+
+	testCases := []testCase{
+		{"correct", []string{"user.json", "list.json"}, 0, ""},
+		{"no schemas", []string{}, exitUsage, ""},
+		{"incorrect", []string{"user.json", "incorrect.json"}, exitSchema, "incorrect.json: jsl: no such definition: a (at \"/ref\")\n"},
+		{"not a schema", []string{"malformed.json"}, exitSchema, "malformed.json: unexpected end of JSON input (at \"\", line 1, column 8)\n"},
+		{"missing", []string{"incorrect.json", "missing.json"}, exitIO, "incorrect.json: jsl: no such definition: a (at \"/ref\")\n"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := testFiles(t)
+
+			var code int
+			out := captureStdout(t, func() {
+				code = verify(inDir(dir, tt.args))
+			})
+
+			assert.Equal(t, tt.code, code)
+			assert.Equal(t, tt.out, strings.ReplaceAll(out, dir+string(filepath.Separator), ""))
+		})
+	}
+}
+
+// testFiles writes the schemas and instances the tests use to a new directory,
+// and returns its path.
+func testFiles(t *testing.T) string {
+	dir := t.TempDir()
+	files := map[string]string{
+		"user.json":      `{"properties":{"name":{"type":"string"}}}`,
+		"list.json":      `{"definitions":{"list":{"elements":{"ref":"list"}}},"ref":"list"}`,
+		"incorrect.json": `{"ref":"a"}`,
+		"valid.json":     `{"name":"a"}`,
+		"invalid.json":   `{"name":1}`,
+		"malformed.json": `{"name":`,
+		"deep.json":      `[[[[]]]]`,
+		"users.jsonl":    "{\"name\":\"a\"}\n{\"name\":2}\n{\"name\":\n{\"name\":\"d\"}\n",
+	}
+
+	for name, data := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+// inDir returns args, with every argument that is the name of a file ending in
+// .json or .jsonl made relative to dir.
+func inDir(dir string, args []string) []string {
+	out := make([]string, len(args))
+	for i, arg := range args {
+		if strings.HasSuffix(arg, ".json") || strings.HasSuffix(arg, ".jsonl") {
+			arg = filepath.Join(dir, arg)
+		}
+
+		out[i] = arg
+	}
+
+	return out
+}
+
+// captureStdout runs f, and returns what it writes to standard output. What f
+// writes to standard error is discarded.
+func captureStdout(t *testing.T, f func()) string {
+	out, err := ioutil.TempFile(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer out.Close()
+
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer devNull.Close()
+
+	stdout, stderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = out, devNull
+	defer func() { os.Stdout, os.Stderr = stdout, stderr }()
+
+	f()
+
+	data, err := ioutil.ReadFile(out.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(data)
+}
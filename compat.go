@@ -0,0 +1,281 @@
+package jsl
+
+import "fmt"
+
+// CompatibilityMode is which kinds of compatibility CheckCompatibility checks
+// for. Modes may be combined with |.
+type CompatibilityMode int
+
+const (
+	// CompatibilityBackward checks that the new schema accepts every instance
+	// the old schema accepts, so that data written against the old schema can
+	// still be read by those using the new schema.
+	CompatibilityBackward CompatibilityMode = 1 << iota
+
+	// CompatibilityForward checks that the old schema accepts every instance
+	// the new schema accepts, so that data written against the new schema can
+	// still be read by those using the old schema.
+	CompatibilityForward
+
+	// CompatibilityFull checks for both backward and forward compatibility.
+	CompatibilityFull = CompatibilityBackward | CompatibilityForward
+)
+
+// Incompatibility is a change between two schemas that breaks compatibility.
+type Incompatibility struct {
+	// Mode is the kind of compatibility that is broken. It is either
+	// CompatibilityBackward or CompatibilityForward.
+	Mode CompatibilityMode
+
+	// OldPath and NewPath are the tokens of JSON Pointers to the parts of the
+	// old and new schema that are incompatible. If the incompatibility arose
+	// from following a "ref", the paths are within the definition referred to.
+	OldPath []string
+	NewPath []string
+
+	// Message describes the incompatibility, such as `property "email" is
+	// required by the new schema, but not by the old schema`.
+	Message string
+}
+
+// CheckCompatibility compares an old and a new version of a schema, and
+// returns the changes that break the kinds of compatibility given by mode.
+//
+// One schema is compatible with another if every instance accepted by the
+// first is also accepted by the second, under the default settings of
+// Validator. So, for instance, adding a required property or narrowing int32
+// to int16 breaks backward compatibility, and removing an enum value breaks
+// backward compatibility but not forward compatibility. Since properties not
+// mentioned by a schema are accepted, removing a property breaks neither.
+//
+// Refs are followed into definitions, including recursively. Changes to
+// definitions that are not used by the root schema do not matter.
+//
+// CheckCompatibility returns the same errors as Verify if either schema is not
+// correct.
+func CheckCompatibility(old, new Schema, mode CompatibilityMode) ([]Incompatibility, error) {
+	if err := old.Verify(); err != nil {
+		return nil, err
+	}
+
+	if err := new.Verify(); err != nil {
+		return nil, err
+	}
+
+	var out []Incompatibility
+
+	if mode&CompatibilityBackward != 0 {
+		c := compatChecker{mode: CompatibilityBackward, from: &old, to: &new, fromName: "old", toName: "new"}
+		c.check(&old, &new, nil, nil)
+		out = append(out, c.out...)
+	}
+
+	if mode&CompatibilityForward != 0 {
+		c := compatChecker{mode: CompatibilityForward, from: &new, to: &old, fromName: "new", toName: "old"}
+		c.check(&new, &old, nil, nil)
+		out = append(out, c.out...)
+	}
+
+	return out, nil
+}
+
+// compatChecker finds what instances of the schema from accepts, but the
+// schema to does not.
+type compatChecker struct {
+	mode             CompatibilityMode
+	from, to         *Schema
+	fromName, toName string
+
+	// seen has the pairs of definitions already being compared, which keeps
+	// recursive schemas from being compared forever.
+	seen map[refPair]struct{}
+
+	out []Incompatibility
+}
+
+func (c *compatChecker) report(fromPath, toPath []string, format string, args ...interface{}) {
+	inc := Incompatibility{Mode: c.mode, Message: fmt.Sprintf(format, args...)}
+
+	inc.OldPath = append([]string{}, fromPath...)
+	inc.NewPath = append([]string{}, toPath...)
+	if c.mode == CompatibilityForward {
+		inc.OldPath, inc.NewPath = inc.NewPath, inc.OldPath
+	}
+
+	c.out = append(c.out, inc)
+}
+
+func (c *compatChecker) check(from, to *Schema, fromPath, toPath []string) {
+	// A ref accepts null if it is nullable, or if what it refers to is.
+	fromNullable, toNullable := from.Nullable, to.Nullable
+
+	var refs refPair
+	for from.Form() == FormRef {
+		refs.a, refs.hasA = *from.Ref, true
+		fromPath = []string{"definitions", *from.Ref}
+		def := c.from.Definitions[*from.Ref]
+		from = &def
+		fromNullable = fromNullable || from.Nullable
+	}
+
+	for to.Form() == FormRef {
+		refs.b, refs.hasB = *to.Ref, true
+		toPath = []string{"definitions", *to.Ref}
+		def := c.to.Definitions[*to.Ref]
+		to = &def
+		toNullable = toNullable || to.Nullable
+	}
+
+	if refs.hasA || refs.hasB {
+		if c.seen == nil {
+			c.seen = map[refPair]struct{}{}
+		}
+
+		if _, ok := c.seen[refs]; ok {
+			return
+		}
+
+		c.seen[refs] = struct{}{}
+		defer delete(c.seen, refs)
+	}
+
+	fromForm, toForm := from.Form(), to.Form()
+	if fromNullable && !toNullable && toForm != FormEmpty {
+		c.report(fromPath, toPath, "null is accepted by the %s schema, but not by the %s schema", c.fromName, c.toName)
+	}
+
+	switch {
+	case toForm == FormEmpty:
+		// The empty form accepts everything.
+	case fromForm == FormType && toForm == FormType:
+		if !typeAccepts(to.Type, from.Type) {
+			c.report(fromPath, toPath, "type %s in the %s schema accepts values that type %s in the %s schema does not", from.Type, c.fromName, to.Type, c.toName)
+		}
+	case fromForm == FormEnum && toForm == FormEnum:
+		values := make(map[string]struct{}, len(to.Enum))
+		for _, val := range to.Enum {
+			values[val] = struct{}{}
+		}
+
+		for _, val := range from.Enum {
+			if _, ok := values[val]; !ok {
+				c.report(fromPath, toPath, "enum value %q is accepted by the %s schema, but not by the %s schema", val, c.fromName, c.toName)
+			}
+		}
+	case fromForm == FormEnum && toForm == FormType && to.Type == TypeString:
+		// Every enum value is a string.
+	case fromForm == FormElements && toForm == FormElements:
+		c.check(from.Elements, to.Elements, extend(fromPath, "elements"), extend(toPath, "elements"))
+	case fromForm == FormValues && toForm == FormValues:
+		c.check(from.Values, to.Values, extend(fromPath, "values"), extend(toPath, "values"))
+	case fromForm == FormProperties && toForm == FormProperties:
+		c.checkProperties(from, to, fromPath, toPath)
+	case fromForm == FormDiscriminator && toForm == FormDiscriminator:
+		c.checkDiscriminator(from, to, fromPath, toPath)
+	default:
+		c.report(fromPath, toPath, "the %s schema is of the %s form, but the %s schema is of the %s form", c.fromName, formNames[fromForm], c.toName, formNames[toForm])
+	}
+}
+
+// checkProperties compares schemas of the properties form.
+func (c *compatChecker) checkProperties(from, to *Schema, fromPath, toPath []string) {
+	for _, k := range sortedSchemaKeys(to.RequiredProperties) {
+		sub := to.RequiredProperties[k]
+		subPath := extend(toPath, "properties", k)
+
+		if fromSub, ok := from.RequiredProperties[k]; ok {
+			c.check(&fromSub, &sub, extend(fromPath, "properties", k), subPath)
+		} else if _, ok := from.OptionalProperties[k]; ok {
+			c.report(extend(fromPath, "optionalProperties", k), subPath, "property %q is optional in the %s schema, but required by the %s schema", k, c.fromName, c.toName)
+		} else {
+			c.report(fromPath, subPath, "property %q is required by the %s schema, but not by the %s schema", k, c.toName, c.fromName)
+		}
+	}
+
+	for _, k := range sortedSchemaKeys(to.OptionalProperties) {
+		sub := to.OptionalProperties[k]
+		subPath := extend(toPath, "optionalProperties", k)
+
+		if fromSub, ok := from.RequiredProperties[k]; ok {
+			c.check(&fromSub, &sub, extend(fromPath, "properties", k), subPath)
+		} else if fromSub, ok := from.OptionalProperties[k]; ok {
+			c.check(&fromSub, &sub, extend(fromPath, "optionalProperties", k), subPath)
+		} else if sub.Form() != FormEmpty {
+			// The from schema accepts any value for properties it does not
+			// mention, so it matters what the to schema requires of them.
+			c.report(fromPath, subPath, "property %q may have any value in the %s schema, but not in the %s schema", k, c.fromName, c.toName)
+		}
+	}
+}
+
+func (c *compatChecker) checkDiscriminator(from, to *Schema, fromPath, toPath []string) {
+	fromPath = extend(fromPath, "discriminator")
+	toPath = extend(toPath, "discriminator")
+
+	if from.Discriminator.Tag != to.Discriminator.Tag {
+		c.report(extend(fromPath, "tag"), extend(toPath, "tag"), "discriminator tag is %q in the %s schema, but %q in the %s schema", from.Discriminator.Tag, c.fromName, to.Discriminator.Tag, c.toName)
+		return
+	}
+
+	for _, k := range sortedSchemaKeys(from.Discriminator.Mapping) {
+		fromSub := from.Discriminator.Mapping[k]
+		subPath := extend(fromPath, "mapping", k)
+
+		sub, ok := to.Discriminator.Mapping[k]
+		if !ok {
+			c.report(subPath, extend(toPath, "mapping"), "discriminator value %q is mapped by the %s schema, but not by the %s schema", k, c.fromName, c.toName)
+			continue
+		}
+
+		c.checkProperties(&fromSub, &sub, subPath, extend(toPath, "mapping", k))
+	}
+}
+
+// typeAccepts returns whether every value accepted by type from is accepted by
+// type to, under the default settings of Validator.
+func typeAccepts(to, from Type) bool {
+	if to == from {
+		return true
+	}
+
+	fromRange, fromInt := intRanges[from]
+	switch to {
+	case TypeNumber, TypeFloat64, TypeFloat32:
+		// By default, float32 only checks that a number is within range, and
+		// every integer type is within the range of float32.
+		return from == TypeNumber || from == TypeFloat64 || from == TypeFloat32 || fromInt
+	case TypeString:
+		return from == TypeTimestamp
+	}
+
+	toRange, toInt := intRanges[to]
+	return fromInt && toInt && toRange[0] <= fromRange[0] && fromRange[1] <= toRange[1]
+}
+
+// intRanges has the ranges of the integer types, as the number of bits below
+// zero and above zero they reach. For instance, int8 is [-7, 7].
+var intRanges = map[Type][2]int{
+	TypeInt8:   {-7, 7},
+	TypeUint8:  {0, 8},
+	TypeInt16:  {-15, 15},
+	TypeUint16: {0, 16},
+	TypeInt32:  {-31, 31},
+	TypeUint32: {0, 32},
+	TypeInt64:  {-63, 63},
+	TypeUint64: {0, 64},
+}
+
+var formNames = map[Form]string{
+	FormEmpty:         "empty",
+	FormRef:           "ref",
+	FormType:          "type",
+	FormEnum:          "enum",
+	FormElements:      "elements",
+	FormProperties:    "properties",
+	FormValues:        "values",
+	FormDiscriminator: "discriminator",
+}
+
+func extend(path []string, tokens ...string) []string {
+	return append(append([]string{}, path...), tokens...)
+}
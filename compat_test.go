@@ -0,0 +1,249 @@
+package jsl_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	jsl "github.com/json-schema-language/json-schema-language-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckCompatibility(t *testing.T) {
+	type testCase struct {
+		name string
+		old  string
+		new  string
+		out  []jsl.Incompatibility
+	}
+
+	testCases := []testCase{
+		{
+			"identical",
+			`{"properties":{"a":{"type":"int32"}}}`,
+			`{"properties":{"a":{"type":"int32"}}}`,
+			nil,
+		},
+		{
+			"new required property",
+			`{"properties":{"a":{}}}`,
+			`{"properties":{"a":{},"b":{}}}`,
+			[]jsl.Incompatibility{
+				{
+					Mode:    jsl.CompatibilityBackward,
+					OldPath: []string{},
+					NewPath: []string{"properties", "b"},
+					Message: `property "b" is required by the new schema, but not by the old schema`,
+				},
+			},
+		},
+		{
+			"removed required property",
+			`{"properties":{"a":{},"b":{}}}`,
+			`{"properties":{"a":{}}}`,
+			[]jsl.Incompatibility{
+				{
+					Mode:    jsl.CompatibilityForward,
+					OldPath: []string{"properties", "b"},
+					NewPath: []string{},
+					Message: `property "b" is required by the old schema, but not by the new schema`,
+				},
+			},
+		},
+		{
+			"optional to required",
+			`{"optionalProperties":{"a":{}}}`,
+			`{"properties":{"a":{}}}`,
+			[]jsl.Incompatibility{
+				{
+					Mode:    jsl.CompatibilityBackward,
+					OldPath: []string{"optionalProperties", "a"},
+					NewPath: []string{"properties", "a"},
+					Message: `property "a" is optional in the old schema, but required by the new schema`,
+				},
+			},
+		},
+		{
+			"narrowed type",
+			`{"elements":{"type":"int32"}}`,
+			`{"elements":{"type":"int16"}}`,
+			[]jsl.Incompatibility{
+				{
+					Mode:    jsl.CompatibilityBackward,
+					OldPath: []string{"elements"},
+					NewPath: []string{"elements"},
+					Message: "type int32 in the old schema accepts values that type int16 in the new schema does not",
+				},
+			},
+		},
+		{
+			"widened type",
+			`{"values":{"type":"uint8"}}`,
+			`{"values":{"type":"int16"}}`,
+			[]jsl.Incompatibility{
+				{
+					Mode:    jsl.CompatibilityForward,
+					OldPath: []string{"values"},
+					NewPath: []string{"values"},
+					Message: "type int16 in the new schema accepts values that type uint8 in the old schema does not",
+				},
+			},
+		},
+		{
+			"removed enum value",
+			`{"enum":["a","b","c"]}`,
+			`{"enum":["a","c","d"]}`,
+			[]jsl.Incompatibility{
+				{
+					Mode:    jsl.CompatibilityBackward,
+					OldPath: []string{},
+					NewPath: []string{},
+					Message: `enum value "b" is accepted by the old schema, but not by the new schema`,
+				},
+				{
+					Mode:    jsl.CompatibilityForward,
+					OldPath: []string{},
+					NewPath: []string{},
+					Message: `enum value "d" is accepted by the new schema, but not by the old schema`,
+				},
+			},
+		},
+		{
+			"enum to string",
+			`{"enum":["a"]}`,
+			`{"type":"string"}`,
+			[]jsl.Incompatibility{
+				{
+					Mode:    jsl.CompatibilityForward,
+					OldPath: []string{},
+					NewPath: []string{},
+					Message: "the new schema is of the type form, but the old schema is of the enum form",
+				},
+			},
+		},
+		{
+			"removed mapping",
+			`{"discriminator":{"tag":"t","mapping":{"a":{"properties":{}},"b":{"properties":{}}}}}`,
+			`{"discriminator":{"tag":"t","mapping":{"a":{"properties":{}}}}}`,
+			[]jsl.Incompatibility{
+				{
+					Mode:    jsl.CompatibilityBackward,
+					OldPath: []string{"discriminator", "mapping", "b"},
+					NewPath: []string{"discriminator", "mapping"},
+					Message: `discriminator value "b" is mapped by the old schema, but not by the new schema`,
+				},
+			},
+		},
+		{
+			"changed tag",
+			`{"discriminator":{"tag":"t","mapping":{}}}`,
+			`{"discriminator":{"tag":"u","mapping":{}}}`,
+			[]jsl.Incompatibility{
+				{
+					Mode:    jsl.CompatibilityBackward,
+					OldPath: []string{"discriminator", "tag"},
+					NewPath: []string{"discriminator", "tag"},
+					Message: `discriminator tag is "t" in the old schema, but "u" in the new schema`,
+				},
+				{
+					Mode:    jsl.CompatibilityForward,
+					OldPath: []string{"discriminator", "tag"},
+					NewPath: []string{"discriminator", "tag"},
+					Message: `discriminator tag is "u" in the new schema, but "t" in the old schema`,
+				},
+			},
+		},
+		{
+			"changed definition",
+			`{"definitions":{"a":{"type":"string"},"node":{"optionalProperties":{"next":{"ref":"node"},"v":{"ref":"a"}}}},"ref":"node"}`,
+			`{"definitions":{"b":{"type":"timestamp"},"node":{"optionalProperties":{"next":{"ref":"node"},"v":{"ref":"b"}}}},"ref":"node"}`,
+			[]jsl.Incompatibility{
+				{
+					Mode:    jsl.CompatibilityBackward,
+					OldPath: []string{"definitions", "a"},
+					NewPath: []string{"definitions", "b"},
+					Message: "type string in the old schema accepts values that type timestamp in the new schema does not",
+				},
+			},
+		},
+		{
+			"no longer nullable",
+			`{"properties":{"a":{"type":"string","nullable":true}}}`,
+			`{"properties":{"a":{"type":"string"}}}`,
+			[]jsl.Incompatibility{
+				{
+					Mode:    jsl.CompatibilityBackward,
+					OldPath: []string{"properties", "a"},
+					NewPath: []string{"properties", "a"},
+					Message: "null is accepted by the old schema, but not by the new schema",
+				},
+			},
+		},
+		{
+			"nullable through ref",
+			`{"definitions":{"a":{"type":"string","nullable":true}},"elements":{"ref":"a"}}`,
+			`{"elements":{"type":"string","nullable":true}}`,
+			nil,
+		},
+		{
+			"new optional property of any value",
+			`{"properties":{}}`,
+			`{"optionalProperties":{"a":{}, "b":{"type":"string"}}}`,
+			[]jsl.Incompatibility{
+				{
+					Mode:    jsl.CompatibilityBackward,
+					OldPath: []string{},
+					NewPath: []string{"optionalProperties", "b"},
+					Message: `property "b" may have any value in the old schema, but not in the new schema`,
+				},
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			var old, new jsl.Schema
+			assert.NoError(t, json.Unmarshal([]byte(tt.old), &old))
+			assert.NoError(t, json.Unmarshal([]byte(tt.new), &new))
+
+			out, err := jsl.CheckCompatibility(old, new, jsl.CompatibilityFull)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.out, out)
+		})
+	}
+}
+
+func TestCheckCompatibilityMode(t *testing.T) {
+	old := jsl.Schema{Type: jsl.TypeInt32}
+	new := jsl.Schema{Type: jsl.TypeInt16}
+
+	out, err := jsl.CheckCompatibility(old, new, jsl.CompatibilityForward)
+	assert.NoError(t, err)
+	assert.Empty(t, out)
+
+	out, err = jsl.CheckCompatibility(old, new, jsl.CompatibilityBackward)
+	assert.NoError(t, err)
+	assert.Len(t, out, 1)
+
+	_, err = jsl.CheckCompatibility(old, jsl.Schema{Ref: strptr("a")}, jsl.CompatibilityFull)
+	assert.Equal(t, jsl.ErrNoSuchDefinition("a"), err)
+}
+
+func TestCheckCompatibilityEmptyRefCycle(t *testing.T) {
+	var schema jsl.Schema
+	assert.NoError(t, json.Unmarshal([]byte(`{"definitions":{"":{"elements":{"ref":""}}},"ref":""}`), &schema))
+
+	done := make(chan []jsl.Incompatibility)
+	go func() {
+		out, err := jsl.CheckCompatibility(schema, schema, jsl.CompatibilityFull)
+		assert.NoError(t, err)
+		done <- out
+	}()
+
+	select {
+	case out := <-done:
+		assert.Empty(t, out)
+	case <-time.After(5 * time.Second):
+		t.Fatal("CheckCompatibility did not return")
+	}
+}
@@ -0,0 +1,175 @@
+package jsl
+
+import "sort"
+
+// CompiledSchema is a schema that has been verified and prepared for repeated
+// validation.
+//
+// Compiling a schema resolves every "ref" to its definition, determines the
+// form of every sub-schema, and turns enums into sets ahead of time, so that
+// none of that work is repeated on each call to Validate. A CompiledSchema is
+// immutable, and is safe for concurrent use by multiple goroutines.
+type CompiledSchema struct {
+	schema Schema
+	root   *node
+}
+
+// node is a single instruction in a compiled schema. Only the fields relevant
+// to form are meaningful.
+type node struct {
+	form Form
+
+	// nullable is whether the node accepts null, regardless of its form.
+	nullable bool
+
+	// expected describes what the node requires of an instance, for errors
+	// arising from an instance not being of the right type.
+	expected []string
+
+	// For FormRef. ref points into the definitions of the root schema, which
+	// may be circular.
+	refName string
+	ref     *node
+
+	// For FormType.
+	typ Type
+
+	// For FormEnum.
+	enum map[string]struct{}
+
+	// For FormElements and FormValues.
+	elements *node
+	values   *node
+
+	// For FormProperties. hasRequired records whether the "properties" keyword
+	// was present, which is significant to the schema path of some errors.
+	hasRequired bool
+	required    map[string]*node
+	optional    map[string]*node
+	additional  bool
+
+	// requiredNames, optionalNames and propertyNames are the sorted keys of
+	// required, optional, and both.
+	requiredNames []string
+	optionalNames []string
+	propertyNames []string
+
+	// For FormDiscriminator.
+	tag         string
+	mapping     map[string]*node
+	mappingKeys []string
+}
+
+// Compile verifies a schema, and then compiles it into a form that can be
+// efficiently and repeatedly evaluated by a Validator.
+//
+// Compile returns the same errors as Verify if the schema is not correct.
+func Compile(schema Schema) (*CompiledSchema, error) {
+	if err := schema.Verify(); err != nil {
+		return nil, err
+	}
+
+	return compile(schema), nil
+}
+
+// Schema returns the schema that was compiled.
+func (c *CompiledSchema) Schema() Schema {
+	return c.schema
+}
+
+// compile builds a CompiledSchema without verifying the schema first. Refs to
+// definitions that do not exist are treated as the empty form.
+func compile(schema Schema) *CompiledSchema {
+	definitions := make(map[string]*node, len(schema.Definitions))
+	for name := range schema.Definitions {
+		definitions[name] = &node{}
+	}
+
+	for name, def := range schema.Definitions {
+		compileNode(definitions, definitions[name], &def)
+	}
+
+	root := &node{}
+	compileNode(definitions, root, &schema)
+
+	return &CompiledSchema{schema: schema, root: root}
+}
+
+func compileNode(definitions map[string]*node, n *node, s *Schema) {
+	n.form = s.Form()
+	n.nullable = s.Nullable
+
+	switch n.form {
+	case FormRef:
+		n.refName = *s.Ref
+		if ref, ok := definitions[*s.Ref]; ok {
+			n.ref = ref
+		} else {
+			n.ref = &node{}
+		}
+	case FormType:
+		n.typ = s.Type
+		n.expected = []string{string(s.Type)}
+	case FormEnum:
+		n.enum = make(map[string]struct{}, len(s.Enum))
+		for _, val := range s.Enum {
+			n.enum[val] = struct{}{}
+		}
+
+		n.expected = sortedKeys(n.enum)
+	case FormElements:
+		n.elements = &node{}
+		n.expected = []string{"array"}
+		compileNode(definitions, n.elements, s.Elements)
+	case FormProperties:
+		n.hasRequired = s.RequiredProperties != nil
+		n.required = compileNodes(definitions, s.RequiredProperties)
+		n.optional = compileNodes(definitions, s.OptionalProperties)
+		n.additional = s.AdditionalProperties
+		n.expected = []string{"object"}
+		n.requiredNames = sortedNodeKeys(n.required)
+		n.optionalNames = sortedNodeKeys(n.optional)
+		n.propertyNames = append(append([]string{}, n.requiredNames...), n.optionalNames...)
+		sort.Strings(n.propertyNames)
+	case FormValues:
+		n.values = &node{}
+		n.expected = []string{"object"}
+		compileNode(definitions, n.values, s.Values)
+	case FormDiscriminator:
+		n.tag = s.Discriminator.Tag
+		n.mapping = compileNodes(definitions, s.Discriminator.Mapping)
+		n.expected = []string{"object"}
+		n.mappingKeys = sortedNodeKeys(n.mapping)
+	}
+}
+
+func compileNodes(definitions map[string]*node, schemas map[string]Schema) map[string]*node {
+	nodes := make(map[string]*node, len(schemas))
+	for k, s := range schemas {
+		n := &node{}
+		compileNode(definitions, n, &s)
+		nodes[k] = n
+	}
+
+	return nodes
+}
+
+func sortedKeys(set map[string]struct{}) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedNodeKeys(nodes map[string]*node) []string {
+	keys := make([]string, 0, len(nodes))
+	for k := range nodes {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
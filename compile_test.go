@@ -0,0 +1,65 @@
+package jsl_test
+
+import (
+	"sync"
+	"testing"
+
+	jsl "github.com/json-schema-language/json-schema-language-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompileInvalid(t *testing.T) {
+	_, err := jsl.Compile(jsl.Schema{Ref: strptr("a")})
+	assert.Equal(t, jsl.ErrNoSuchDefinition("a"), err)
+}
+
+func TestValidateCompiled(t *testing.T) {
+	schema, err := jsl.Compile(jsl.Schema{
+		Definitions: map[string]jsl.Schema{
+			"node": jsl.Schema{
+				RequiredProperties: map[string]jsl.Schema{
+					"value": jsl.Schema{Enum: []string{"a", "b"}},
+				},
+				OptionalProperties: map[string]jsl.Schema{
+					"next": jsl.Schema{Ref: strptr("node")},
+				},
+			},
+		},
+		Ref: strptr("node"),
+	})
+	assert.NoError(t, err)
+
+	instance := map[string]interface{}{
+		"value": "a",
+		"next": map[string]interface{}{
+			"value": "c",
+			"next": map[string]interface{}{
+				"value": "b",
+			},
+		},
+	}
+
+	validator := jsl.Validator{}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			result, err := validator.ValidateCompiled(schema, instance)
+			assert.NoError(t, err)
+			assert.Equal(t, []jsl.ValidationError{
+				jsl.ValidationError{
+					InstancePath: []string{"next", "value"},
+					SchemaPath:   []string{"definitions", "node", "properties", "value", "enum"},
+					Kind:         jsl.KindEnum,
+					Expected:     []string{"a", "b"},
+					Actual:       `"c"`,
+				},
+			}, result.Errors)
+		}()
+	}
+
+	wg.Wait()
+}
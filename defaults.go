@@ -0,0 +1,145 @@
+package jsl
+
+// ApplyDefaults fills in the optional properties that are missing from an
+// instance with their defaults, so that they need not be hard-coded separately
+// from the schema.
+//
+// The default of an optional property is the "default" key of the Metadata of
+// its schema, such as:
+//
+//	{"optionalProperties": {"port": {"type": "uint16", "metadata": {"default": 8080}}}}
+//
+// Defaults are applied throughout the instance, following refs, elements,
+// values, and the mapping of a discriminator that matches the tag of the
+// instance. Defaults are themselves filled in once they are added, so a
+// default of {} picks up the defaults of the properties within it. A default
+// is not filled in again within itself, so that a recursive schema, such as a
+// node whose optional child is a node with a default of {}, adds it only once.
+// Parts of the instance that do not match the schema are left as they are, and
+// reported once the instance is validated.
+//
+// The instance is modified in place, and so is expected to be the result of
+// decoding JSON into an interface{}: only objects of type
+// map[string]interface{} and arrays of type []interface{} are filled in.
+// Defaults are copied before they are added, so that they are never shared
+// between instances.
+//
+// ApplyDefaults returns the same errors as Verify if the schema is not
+// correct.
+func ApplyDefaults(schema Schema, instance interface{}) error {
+	if err := schema.Verify(); err != nil {
+		return err
+	}
+
+	d := defaulter{root: &schema, expanding: map[string]struct{}{}}
+	d.apply(&schema, instance, nil)
+	return nil
+}
+
+// defaulter fills in the defaults of root.
+type defaulter struct {
+	root *Schema
+
+	// expanding has the JSON Pointers to the optional properties whose
+	// defaults are being filled in further up the stack, which keeps recursive
+	// schemas from adding defaults forever.
+	expanding map[string]struct{}
+}
+
+// apply fills in the defaults of s, which is at path within root.
+func (d *defaulter) apply(s *Schema, instance interface{}, path []string) {
+	switch s.Form() {
+	case FormRef:
+		def := d.root.Definitions[*s.Ref]
+		d.apply(&def, instance, []string{"definitions", *s.Ref})
+	case FormElements:
+		if arr, ok := instance.([]interface{}); ok {
+			for _, elem := range arr {
+				d.apply(s.Elements, elem, extend(path, "elements"))
+			}
+		}
+	case FormProperties:
+		// A nil map is null, and so has no properties to fill in.
+		obj, ok := instance.(map[string]interface{})
+		if !ok || obj == nil {
+			return
+		}
+
+		added := map[string]string{}
+		for k, sub := range s.OptionalProperties {
+			if _, ok := obj[k]; ok {
+				continue
+			}
+
+			def, ok := sub.Metadata["default"]
+			if !ok {
+				continue
+			}
+
+			ptr := pointer(extend(path, "optionalProperties", k))
+			if _, ok := d.expanding[ptr]; ok {
+				continue
+			}
+
+			obj[k] = copyJSON(def)
+			added[k] = ptr
+		}
+
+		for k, sub := range s.RequiredProperties {
+			if val, ok := obj[k]; ok {
+				d.apply(&sub, val, extend(path, "properties", k))
+			}
+		}
+
+		for k, sub := range s.OptionalProperties {
+			val, ok := obj[k]
+			if !ok {
+				continue
+			}
+
+			if ptr, ok := added[k]; ok {
+				d.expanding[ptr] = struct{}{}
+				d.apply(&sub, val, extend(path, "optionalProperties", k))
+				delete(d.expanding, ptr)
+			} else {
+				d.apply(&sub, val, extend(path, "optionalProperties", k))
+			}
+		}
+	case FormValues:
+		if obj, ok := instance.(map[string]interface{}); ok {
+			for _, val := range obj {
+				d.apply(s.Values, val, extend(path, "values"))
+			}
+		}
+	case FormDiscriminator:
+		if obj, ok := instance.(map[string]interface{}); ok {
+			if tag, ok := obj[s.Discriminator.Tag].(string); ok {
+				if mapping, ok := s.Discriminator.Mapping[tag]; ok {
+					d.apply(&mapping, instance, extend(path, "discriminator", "mapping", tag))
+				}
+			}
+		}
+	}
+}
+
+// copyJSON returns a deep copy of a value decoded from JSON.
+func copyJSON(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			out[k] = copyJSON(val)
+		}
+
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, val := range v {
+			out[i] = copyJSON(val)
+		}
+
+		return out
+	default:
+		return v
+	}
+}
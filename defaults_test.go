@@ -0,0 +1,128 @@
+package jsl_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	jsl "github.com/json-schema-language/json-schema-language-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyDefaults(t *testing.T) {
+	type testCase struct {
+		name     string
+		schema   string
+		instance string
+		out      string
+	}
+
+	testCases := []testCase{
+		{
+			"optional properties",
+			`{"properties":{"host":{"type":"string","metadata":{"default":"localhost"}}},"optionalProperties":{"port":{"metadata":{"default":8080}},"tls":{}}}`,
+			`{}`,
+			`{"port":8080}`,
+		},
+		{
+			"present properties are kept",
+			`{"optionalProperties":{"port":{"metadata":{"default":8080}},"debug":{"nullable":true,"metadata":{"default":false}}}}`,
+			`{"port":1,"debug":null}`,
+			`{"port":1,"debug":null}`,
+		},
+		{
+			"nested defaults",
+			`{"optionalProperties":{"log":{"optionalProperties":{"level":{"enum":["info","debug"],"metadata":{"default":"info"}}},"metadata":{"default":{}}}}}`,
+			`{}`,
+			`{"log":{"level":"info"}}`,
+		},
+		{
+			"ref, elements and values",
+			`{
+				"definitions": {"server": {"optionalProperties": {"weight": {"metadata": {"default": 1}}}}},
+				"properties": {
+					"servers": {"elements": {"ref": "server"}},
+					"byName": {"values": {"ref": "server"}}
+				}
+			}`,
+			`{"servers":[{},{"weight":2},3],"byName":{"a":{}}}`,
+			`{"servers":[{"weight":1},{"weight":2},3],"byName":{"a":{"weight":1}}}`,
+		},
+		{
+			"discriminator",
+			`{"elements":{"discriminator":{"tag":"kind","mapping":{"file":{"optionalProperties":{"path":{"metadata":{"default":"/tmp"}}}}}}}}`,
+			`[{"kind":"file"},{"kind":"other"},{"kind":1}]`,
+			`[{"kind":"file","path":"/tmp"},{"kind":"other"},{"kind":1}]`,
+		},
+		{
+			"recursive defaults",
+			`{
+				"definitions": {
+					"node": {
+						"optionalProperties": {
+							"name": {"metadata": {"default": "x"}},
+							"child": {"ref": "node", "metadata": {"default": {}}}
+						}
+					}
+				},
+				"ref": "node"
+			}`,
+			`{"child":{"child":{"name":"y"}}}`,
+			`{"name":"x","child":{"name":"x","child":{"name":"y","child":{"name":"x"}}}}`,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, err := jsl.ParseSchema([]byte(tt.schema))
+			assert.NoError(t, err)
+
+			var instance interface{}
+			assert.NoError(t, json.Unmarshal([]byte(tt.instance), &instance))
+			assert.NoError(t, jsl.ApplyDefaults(schema, instance))
+
+			out, err := json.Marshal(instance)
+			assert.NoError(t, err)
+			assert.JSONEq(t, tt.out, string(out))
+		})
+	}
+}
+
+func TestApplyDefaultsRecursive(t *testing.T) {
+	schema, err := jsl.ParseSchema([]byte(`{"definitions":{"node":{"optionalProperties":{"child":{"ref":"node","metadata":{"default":{}}}}}},"ref":"node"}`))
+	assert.NoError(t, err)
+
+	instance := map[string]interface{}{}
+	assert.NoError(t, jsl.ApplyDefaults(schema, instance))
+	assert.Equal(t, map[string]interface{}{"child": map[string]interface{}{}}, instance)
+}
+
+func TestApplyDefaultsNil(t *testing.T) {
+	schema, err := jsl.ParseSchema([]byte(`{"elements":{"optionalProperties":{"a":{"metadata":{"default":1}}}}}`))
+	assert.NoError(t, err)
+
+	instance := []interface{}{map[string]interface{}(nil), map[string]interface{}{}}
+	assert.NoError(t, jsl.ApplyDefaults(schema, instance))
+	assert.Equal(t, []interface{}{map[string]interface{}(nil), map[string]interface{}{"a": 1.0}}, instance)
+}
+
+func TestApplyDefaultsCopies(t *testing.T) {
+	schema := jsl.Schema{
+		OptionalProperties: map[string]jsl.Schema{
+			"tags": jsl.Schema{Metadata: map[string]interface{}{"default": []interface{}{"a"}}},
+		},
+	}
+
+	a := map[string]interface{}{}
+	b := map[string]interface{}{}
+	assert.NoError(t, jsl.ApplyDefaults(schema, a))
+	assert.NoError(t, jsl.ApplyDefaults(schema, b))
+
+	a["tags"].([]interface{})[0] = "b"
+	assert.Equal(t, []interface{}{"a"}, b["tags"])
+	assert.Equal(t, []interface{}{"a"}, schema.OptionalProperties["tags"].Metadata["default"])
+}
+
+func TestApplyDefaultsInvalidSchema(t *testing.T) {
+	err := jsl.ApplyDefaults(jsl.Schema{Ref: strptr("a")}, map[string]interface{}{})
+	assert.Equal(t, jsl.ErrNoSuchDefinition("a"), err)
+}
@@ -0,0 +1,416 @@
+package jsl
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ChangeKind is the kind of a Change.
+type ChangeKind int
+
+const (
+	// ChangeAdded indicates that something is in the second schema, but not the
+	// first.
+	ChangeAdded ChangeKind = iota + 1
+
+	// ChangeRemoved indicates that something is in the first schema, but not
+	// the second.
+	ChangeRemoved
+
+	// ChangeChanged indicates that something is in both schemas, but differs.
+	ChangeChanged
+)
+
+var changeKindNames = map[ChangeKind]string{
+	ChangeAdded:   "added",
+	ChangeRemoved: "removed",
+	ChangeChanged: "changed",
+}
+
+// String returns the name of the kind, such as "added".
+func (k ChangeKind) String() string {
+	if name, ok := changeKindNames[k]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("ChangeKind(%d)", int(k))
+}
+
+// Change is a difference between two schemas, as found by Diff.
+type Change struct {
+	Kind ChangeKind
+
+	// Path is the tokens of a JSON Pointer to what changed. Refs are followed
+	// into the definitions they refer to without changing Path, so Path is
+	// where the change is seen from the schema being diffed.
+	Path []string
+
+	// Old and New are what was there before and after the change. They are nil
+	// for ChangeAdded and ChangeRemoved respectively.
+	//
+	// They are a Schema for added, removed and otherwise changed schemas. For
+	// changes to a keyword with a string value, such as "type" or a value of
+	// "enum", they are that string. When a property moves between "properties"
+	// and "optionalProperties", they are "required" or "optional". For changes
+	// to "nullable" and "additionalProperties", they are a bool, and for
+	// changes to a key of "metadata", they are the value of that key.
+	Old interface{}
+	New interface{}
+}
+
+// Diff returns the differences between two schemas, in a deterministic order.
+//
+// Definitions are paired up by name, and are compared with one another. Where
+// the schemas use a ref to the same definition, nothing is reported, since any
+// changes to the definition are reported under "definitions". Otherwise, refs
+// are resolved, and the schemas they refer to are compared. So inlining a
+// definition, or renaming it, is not a change in itself. The "nullable" and
+// "metadata" of a ref are carried over to what it refers to when it is
+// resolved, unless what it refers to has metadata of its own.
+//
+// Diff does not verify the schemas it is given. Refs to definitions that do not
+// exist are treated as the empty form.
+func Diff(a, b Schema) []Change {
+	d := differ{a: &a, b: &b}
+
+	for _, name := range sortedSchemaKeys(a.Definitions) {
+		defA := a.Definitions[name]
+		if defB, ok := b.Definitions[name]; ok {
+			d.diff(&defA, &defB, []string{"definitions", name})
+		} else {
+			d.add(ChangeRemoved, []string{"definitions", name}, defA, nil)
+		}
+	}
+
+	for _, name := range sortedSchemaKeys(b.Definitions) {
+		if _, ok := a.Definitions[name]; !ok {
+			d.add(ChangeAdded, []string{"definitions", name}, nil, b.Definitions[name])
+		}
+	}
+
+	d.diff(&a, &b, nil)
+	return d.changes
+}
+
+// differ accumulates the changes between two schemas.
+type differ struct {
+	a, b *Schema
+
+	// seen has the pairs of refs already being compared, which keeps recursive
+	// schemas from being compared forever.
+	seen map[refPair]struct{}
+
+	changes []Change
+}
+
+// refPair is the refs followed on either side when comparing two schemas, as
+// by differ and compatChecker. hasA and hasB are whether there was a ref on
+// that side, since "" is a valid definition name.
+type refPair struct {
+	a, b       string
+	hasA, hasB bool
+}
+
+// resolve returns the definition of root that the ref s refers to. A ref
+// accepts null if it is nullable, or if what it refers to is, and so the
+// nullability of the ref is carried over to the definition. So is its
+// metadata, if the definition has none.
+func resolve(root, s *Schema) *Schema {
+	def := root.Definitions[*s.Ref]
+	def.Nullable = def.Nullable || s.Nullable
+	if def.Metadata == nil {
+		def.Metadata = s.Metadata
+	}
+
+	return &def
+}
+
+func (d *differ) add(kind ChangeKind, path []string, old, new interface{}) {
+	// Definitions are diffed separately, and so are left out of the schemas
+	// reported as changes.
+	if s, ok := old.(Schema); ok {
+		s.Definitions = nil
+		old = s
+	}
+
+	if s, ok := new.(Schema); ok {
+		s.Definitions = nil
+		new = s
+	}
+
+	d.changes = append(d.changes, Change{Kind: kind, Path: extend(path), Old: old, New: new})
+}
+
+func (d *differ) diff(a, b *Schema, path []string) {
+	if a.Form() == FormRef && b.Form() == FormRef && *a.Ref == *b.Ref {
+		d.diffNullable(a, b, path)
+		d.diffMetadata(a, b, path)
+		return
+	}
+
+	// Each ref is followed one step at a time, and the pair of refs followed
+	// is remembered, so that refs that lead back to themselves are not
+	// followed forever.
+	var refs refPair
+	if a.Form() == FormRef {
+		refs.a, refs.hasA = *a.Ref, true
+		a = resolve(d.a, a)
+	}
+
+	if b.Form() == FormRef {
+		refs.b, refs.hasB = *b.Ref, true
+		b = resolve(d.b, b)
+	}
+
+	if refs.hasA || refs.hasB {
+		if d.seen == nil {
+			d.seen = map[refPair]struct{}{}
+		}
+
+		if _, ok := d.seen[refs]; ok {
+			return
+		}
+
+		d.seen[refs] = struct{}{}
+		defer delete(d.seen, refs)
+
+		// What a ref refers to may itself be a ref.
+		d.diff(a, b, path)
+		return
+	}
+
+	if a.Form() != b.Form() {
+		d.add(ChangeChanged, path, *a, *b)
+		return
+	}
+
+	d.diffNullable(a, b, path)
+	d.diffMetadata(a, b, path)
+
+	switch a.Form() {
+	case FormType:
+		if a.Type != b.Type {
+			d.add(ChangeChanged, extend(path, "type"), string(a.Type), string(b.Type))
+		}
+	case FormEnum:
+		d.diffEnum(a, b, path)
+	case FormElements:
+		d.diff(a.Elements, b.Elements, extend(path, "elements"))
+	case FormProperties:
+		d.diffProperties(a, b, path)
+	case FormValues:
+		d.diff(a.Values, b.Values, extend(path, "values"))
+	case FormDiscriminator:
+		d.diffDiscriminator(a, b, path)
+	}
+}
+
+func (d *differ) diffNullable(a, b *Schema, path []string) {
+	if a.Nullable != b.Nullable {
+		d.add(ChangeChanged, extend(path, "nullable"), a.Nullable, b.Nullable)
+	}
+}
+
+func (d *differ) diffMetadata(a, b *Schema, path []string) {
+	for _, k := range sortedMetadataKeys(a.Metadata) {
+		if valB, ok := b.Metadata[k]; !ok {
+			d.add(ChangeRemoved, extend(path, "metadata", k), a.Metadata[k], nil)
+		} else if !reflect.DeepEqual(a.Metadata[k], valB) {
+			d.add(ChangeChanged, extend(path, "metadata", k), a.Metadata[k], valB)
+		}
+	}
+
+	for _, k := range sortedMetadataKeys(b.Metadata) {
+		if _, ok := a.Metadata[k]; !ok {
+			d.add(ChangeAdded, extend(path, "metadata", k), nil, b.Metadata[k])
+		}
+	}
+}
+
+func sortedMetadataKeys(metadata map[string]interface{}) []string {
+	keys := make([]string, 0, len(metadata))
+	for k := range metadata {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+func (d *differ) diffEnum(a, b *Schema, path []string) {
+	inA := make(map[string]struct{}, len(a.Enum))
+	for _, val := range a.Enum {
+		inA[val] = struct{}{}
+	}
+
+	inB := make(map[string]struct{}, len(b.Enum))
+	for _, val := range b.Enum {
+		inB[val] = struct{}{}
+	}
+
+	for _, val := range sortedKeys(inA) {
+		if _, ok := inB[val]; !ok {
+			d.add(ChangeRemoved, extend(path, "enum"), val, nil)
+		}
+	}
+
+	for _, val := range sortedKeys(inB) {
+		if _, ok := inA[val]; !ok {
+			d.add(ChangeAdded, extend(path, "enum"), nil, val)
+		}
+	}
+}
+
+func (d *differ) diffProperties(a, b *Schema, path []string) {
+	names := map[string]struct{}{}
+	for _, properties := range []map[string]Schema{a.RequiredProperties, a.OptionalProperties, b.RequiredProperties, b.OptionalProperties} {
+		for k := range properties {
+			names[k] = struct{}{}
+		}
+	}
+
+	if a.AdditionalProperties != b.AdditionalProperties {
+		d.add(ChangeChanged, extend(path, "additionalProperties"), a.AdditionalProperties, b.AdditionalProperties)
+	}
+
+	for _, k := range sortedKeys(names) {
+		subA, keywordA, inA := property(a, k)
+		subB, keywordB, inB := property(b, k)
+
+		switch {
+		case !inB:
+			d.add(ChangeRemoved, extend(path, keywordA, k), subA, nil)
+		case !inA:
+			d.add(ChangeAdded, extend(path, keywordB, k), nil, subB)
+		default:
+			if keywordA != keywordB {
+				d.add(ChangeChanged, extend(path, keywordB, k), requiredness(keywordA), requiredness(keywordB))
+			}
+
+			d.diff(&subA, &subB, extend(path, keywordB, k))
+		}
+	}
+}
+
+// property returns the schema of a property, and whether it is in
+// "properties" or "optionalProperties".
+func property(s *Schema, name string) (Schema, string, bool) {
+	if sub, ok := s.RequiredProperties[name]; ok {
+		return sub, "properties", true
+	}
+
+	sub, ok := s.OptionalProperties[name]
+	return sub, "optionalProperties", ok
+}
+
+func requiredness(keyword string) string {
+	if keyword == "properties" {
+		return "required"
+	}
+
+	return "optional"
+}
+
+func (d *differ) diffDiscriminator(a, b *Schema, path []string) {
+	path = extend(path, "discriminator")
+
+	if a.Discriminator.Tag != b.Discriminator.Tag {
+		d.add(ChangeChanged, extend(path, "tag"), a.Discriminator.Tag, b.Discriminator.Tag)
+	}
+
+	for _, k := range sortedSchemaKeys(a.Discriminator.Mapping) {
+		subA := a.Discriminator.Mapping[k]
+		if subB, ok := b.Discriminator.Mapping[k]; ok {
+			d.diff(&subA, &subB, extend(path, "mapping", k))
+		} else {
+			d.add(ChangeRemoved, extend(path, "mapping", k), subA, nil)
+		}
+	}
+
+	for _, k := range sortedSchemaKeys(b.Discriminator.Mapping) {
+		if _, ok := a.Discriminator.Mapping[k]; !ok {
+			d.add(ChangeAdded, extend(path, "mapping", k), nil, b.Discriminator.Mapping[k])
+		}
+	}
+}
+
+// Markdown renders changes as a Markdown list, grouped under a heading for each
+// kind of change, suitable for release notes. Each change is introduced by
+// the JSON Pointer to it, followed by what was added or removed, or what
+// changed into what.
+func Markdown(changes []Change) string {
+	if len(changes) == 0 {
+		return "No changes.\n"
+	}
+
+	headings := []struct {
+		kind ChangeKind
+		text string
+	}{
+		{ChangeAdded, "Added"},
+		{ChangeRemoved, "Removed"},
+		{ChangeChanged, "Changed"},
+	}
+
+	var b strings.Builder
+	for _, h := range headings {
+		kind := h.kind
+		heading := false
+		for _, c := range changes {
+			if c.Kind != kind {
+				continue
+			}
+
+			if !heading {
+				if b.Len() > 0 {
+					b.WriteString("\n")
+				}
+
+				fmt.Fprintf(&b, "### %s\n\n", h.text)
+				heading = true
+			}
+
+			ptr := pointer(c.Path)
+			if ptr == "" {
+				ptr = "(root)"
+			}
+
+			switch kind {
+			case ChangeAdded:
+				fmt.Fprintf(&b, "- `%s`: %s\n", ptr, markdownValue(c.New))
+			case ChangeRemoved:
+				fmt.Fprintf(&b, "- `%s`: %s\n", ptr, markdownValue(c.Old))
+			default:
+				fmt.Fprintf(&b, "- `%s`: %s → %s\n", ptr, markdownValue(c.Old), markdownValue(c.New))
+			}
+		}
+	}
+
+	return b.String()
+}
+
+// markdownValue renders the Old or New of a Change as inline code.
+func markdownValue(v interface{}) string {
+	var text string
+	if s, ok := v.(string); ok {
+		text = s
+	} else {
+		data, _ := json.Marshal(v)
+		text = string(data)
+	}
+
+	// Backticks within inline code need a longer run of backticks around it.
+	fence := "`"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+
+	if strings.HasPrefix(text, "`") || strings.HasSuffix(text, "`") || text == "" {
+		return fence + " " + text + " " + fence
+	}
+
+	return fence + text + fence
+}
@@ -0,0 +1,194 @@
+package jsl_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	jsl "github.com/json-schema-language/json-schema-language-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	type testCase struct {
+		name string
+		a    string
+		b    string
+		out  []jsl.Change
+	}
+
+	testCases := []testCase{
+		{
+			"identical",
+			`{"definitions":{"a":{}},"properties":{"a":{"ref":"a"}}}`,
+			`{"definitions":{"a":{}},"properties":{"a":{"ref":"a"}}}`,
+			nil,
+		},
+		{
+			"changed type",
+			`{"elements":{"type":"int32"}}`,
+			`{"elements":{"type":"int16"}}`,
+			[]jsl.Change{
+				{Kind: jsl.ChangeChanged, Path: []string{"elements", "type"}, Old: "int32", New: "int16"},
+			},
+		},
+		{
+			"changed form",
+			`{"values":{"type":"string"}}`,
+			`{"values":{"enum":["a"]}}`,
+			[]jsl.Change{
+				{
+					Kind: jsl.ChangeChanged,
+					Path: []string{"values"},
+					Old:  jsl.Schema{Type: jsl.TypeString},
+					New:  jsl.Schema{Enum: []string{"a"}},
+				},
+			},
+		},
+		{
+			"enum values",
+			`{"enum":["a","b"]}`,
+			`{"enum":["c","b"]}`,
+			[]jsl.Change{
+				{Kind: jsl.ChangeRemoved, Path: []string{"enum"}, Old: "a"},
+				{Kind: jsl.ChangeAdded, Path: []string{"enum"}, New: "c"},
+			},
+		},
+		{
+			"properties",
+			`{"properties":{"a":{},"b":{}},"optionalProperties":{"c":{"type":"string"}}}`,
+			`{"properties":{"a":{},"c":{"type":"boolean"}},"optionalProperties":{"d":{}}}`,
+			[]jsl.Change{
+				{Kind: jsl.ChangeRemoved, Path: []string{"properties", "b"}, Old: jsl.Schema{}},
+				{Kind: jsl.ChangeChanged, Path: []string{"properties", "c"}, Old: "optional", New: "required"},
+				{Kind: jsl.ChangeChanged, Path: []string{"properties", "c", "type"}, Old: "string", New: "boolean"},
+				{Kind: jsl.ChangeAdded, Path: []string{"optionalProperties", "d"}, New: jsl.Schema{}},
+			},
+		},
+		{
+			"discriminator",
+			`{"discriminator":{"tag":"t","mapping":{"a":{"properties":{}},"b":{"properties":{"x":{}}}}}}`,
+			`{"discriminator":{"tag":"u","mapping":{"b":{"properties":{}},"c":{"properties":{}}}}}`,
+			[]jsl.Change{
+				{Kind: jsl.ChangeChanged, Path: []string{"discriminator", "tag"}, Old: "t", New: "u"},
+				{Kind: jsl.ChangeRemoved, Path: []string{"discriminator", "mapping", "a"}, Old: jsl.Schema{RequiredProperties: map[string]jsl.Schema{}}},
+				{Kind: jsl.ChangeRemoved, Path: []string{"discriminator", "mapping", "b", "properties", "x"}, Old: jsl.Schema{}},
+				{Kind: jsl.ChangeAdded, Path: []string{"discriminator", "mapping", "c"}, New: jsl.Schema{RequiredProperties: map[string]jsl.Schema{}}},
+			},
+		},
+		{
+			"definitions",
+			`{"definitions":{"a":{"type":"string"},"b":{}},"ref":"a"}`,
+			`{"definitions":{"a":{"type":"boolean"},"c":{}},"ref":"a"}`,
+			[]jsl.Change{
+				{Kind: jsl.ChangeChanged, Path: []string{"definitions", "a", "type"}, Old: "string", New: "boolean"},
+				{Kind: jsl.ChangeRemoved, Path: []string{"definitions", "b"}, Old: jsl.Schema{}},
+				{Kind: jsl.ChangeAdded, Path: []string{"definitions", "c"}, New: jsl.Schema{}},
+			},
+		},
+		{
+			"inlined definition",
+			`{"definitions":{"a":{"type":"string"}},"elements":{"ref":"a"}}`,
+			`{"elements":{"type":"string"}}`,
+			[]jsl.Change{
+				{Kind: jsl.ChangeRemoved, Path: []string{"definitions", "a"}, Old: jsl.Schema{Type: jsl.TypeString}},
+			},
+		},
+		{
+			"ref to a different definition",
+			`{"definitions":{"a":{"type":"string"},"b":{"type":"int8"}},"values":{"ref":"a"}}`,
+			`{"definitions":{"a":{"type":"string"},"b":{"type":"int8"}},"values":{"ref":"b"}}`,
+			[]jsl.Change{
+				{Kind: jsl.ChangeChanged, Path: []string{"values", "type"}, Old: "string", New: "int8"},
+			},
+		},
+		{
+			"nullable",
+			`{"definitions":{"a":{"type":"string"}},"properties":{"a":{"ref":"a"},"b":{"ref":"a"},"c":{"type":"int8"}}}`,
+			`{"definitions":{"a":{"type":"string"}},"properties":{"a":{"ref":"a","nullable":true},"b":{"type":"string","nullable":true},"c":{"type":"int8"}}}`,
+			[]jsl.Change{
+				{Kind: jsl.ChangeChanged, Path: []string{"properties", "a", "nullable"}, Old: false, New: true},
+				{Kind: jsl.ChangeChanged, Path: []string{"properties", "b", "nullable"}, Old: false, New: true},
+			},
+		},
+		{
+			"additionalProperties",
+			`{"properties":{"a":{}}}`,
+			`{"properties":{"a":{}},"additionalProperties":true}`,
+			[]jsl.Change{
+				{Kind: jsl.ChangeChanged, Path: []string{"additionalProperties"}, Old: false, New: true},
+			},
+		},
+		{
+			"metadata",
+			`{"definitions":{"a":{}},"elements":{"ref":"a","metadata":{"description":"x","deprecated":true}}}`,
+			`{"definitions":{"a":{}},"elements":{"ref":"a","metadata":{"description":"y","since":"1.2"}}}`,
+			[]jsl.Change{
+				{Kind: jsl.ChangeRemoved, Path: []string{"elements", "metadata", "deprecated"}, Old: true},
+				{Kind: jsl.ChangeChanged, Path: []string{"elements", "metadata", "description"}, Old: "x", New: "y"},
+				{Kind: jsl.ChangeAdded, Path: []string{"elements", "metadata", "since"}, New: "1.2"},
+			},
+		},
+		{
+			"metadata of inlined definition",
+			`{"definitions":{"a":{"type":"string"}},"values":{"ref":"a","metadata":{"description":"x"}}}`,
+			`{"definitions":{"a":{"type":"string"}},"values":{"type":"string","metadata":{"description":"x"}}}`,
+			nil,
+		},
+		{
+			"recursive definitions",
+			`{"definitions":{"a":{"elements":{"ref":"a"}}},"ref":"a"}`,
+			`{"definitions":{"b":{"elements":{"ref":"b"}}},"ref":"b"}`,
+			[]jsl.Change{
+				{Kind: jsl.ChangeRemoved, Path: []string{"definitions", "a"}, Old: jsl.Schema{Elements: &jsl.Schema{Ref: strptr("a")}}},
+				{Kind: jsl.ChangeAdded, Path: []string{"definitions", "b"}, New: jsl.Schema{Elements: &jsl.Schema{Ref: strptr("b")}}},
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			var a, b jsl.Schema
+			assert.NoError(t, json.Unmarshal([]byte(tt.a), &a))
+			assert.NoError(t, json.Unmarshal([]byte(tt.b), &b))
+
+			assert.Equal(t, tt.out, jsl.Diff(a, b))
+		})
+	}
+}
+
+func TestDiffEmptyRefCycle(t *testing.T) {
+	empty := ""
+	a := jsl.Schema{Definitions: map[string]jsl.Schema{"": jsl.Schema{Ref: &empty}}, Ref: &empty}
+
+	done := make(chan []jsl.Change)
+	go func() {
+		done <- jsl.Diff(a, jsl.Schema{})
+	}()
+
+	select {
+	case changes := <-done:
+		assert.Equal(t, []jsl.Change{
+			{Kind: jsl.ChangeRemoved, Path: []string{"definitions", ""}, Old: jsl.Schema{Ref: &empty}},
+		}, changes)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Diff did not return")
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	var a, b jsl.Schema
+	assert.NoError(t, json.Unmarshal([]byte(`{"properties":{"a":{"type":"int32"},"b":{}},"optionalProperties":{"c":{"enum":["x"]}}}`), &a))
+	assert.NoError(t, json.Unmarshal([]byte(`{"properties":{"a":{"type":"int16"},"c":{"enum":["x","y"]}},"optionalProperties":{"d":{"type":"string"}}}`), &b))
+
+	assert.Equal(t, "### Added\n\n"+
+		"- `/properties/c/enum`: `y`\n"+
+		"- `/optionalProperties/d`: `{\"type\":\"string\"}`\n"+
+		"\n### Removed\n\n"+
+		"- `/properties/b`: `{}`\n"+
+		"\n### Changed\n\n"+
+		"- `/properties/a/type`: `int32` → `int16`\n"+
+		"- `/properties/c`: `optional` → `required`\n", jsl.Markdown(jsl.Diff(a, b)))
+
+	assert.Equal(t, "No changes.\n", jsl.Markdown(nil))
+}
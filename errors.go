@@ -16,17 +16,41 @@ var ErrInvalidForm = errors.New("jsl: ambiguous or invalid schema form")
 // form, or the schema is not correct.
 var ErrNonPropertiesMapping = errors.New("jsl: value of discriminator mapping is not of properties form")
 
+// ErrNullableMapping indicates that the schema had a Discriminator.Mapping
+// containing schemas that were nullable. The discriminator itself may be
+// nullable, but the values of its mapping may not.
+var ErrNullableMapping = errors.New("jsl: value of discriminator mapping is nullable")
+
+// ErrEmptyEnum indicates that a schema verified by VerifyRFC8927 had an "enum"
+// without any values. JSL allows such enums, but RFC 8927 does not.
+var ErrEmptyEnum = errors.New("jsl: enum has no values")
+
 // ErrMaxDepthExceeded indicates that the maximum evaluation depth was exceeded
 // while validating an instance. This typically indicates that an infinite
 // recurisve loop was encountered while evaluating the schema.
 var ErrMaxDepthExceeded = errors.New("jsl: maximum evaluation depth exceeded")
 
+// ErrNonRootDefinitions indicates that a schema being parsed by ParseSchema had
+// "definitions" somewhere other than at its root. Definitions are only
+// meaningful on the root schema.
+var ErrNonRootDefinitions = errors.New("jsl: definitions outside of root schema")
+
 // ErrNoSuchDefinition indicates that a "ref" referred to a definition that does
 // not exist.
 type ErrNoSuchDefinition string
 
 func (e ErrNoSuchDefinition) Error() string {
-	return fmt.Sprintf("jsl: no such definition: %s", e)
+	return fmt.Sprintf("jsl: no such definition: %s", string(e))
+}
+
+// ErrCyclicRef indicates that a definition was a "ref" which, by way of the
+// refs of other definitions, eventually referred back to itself. Such a
+// definition never makes progress through an instance, so validating against
+// it would never end.
+type ErrCyclicRef string
+
+func (e ErrCyclicRef) Error() string {
+	return fmt.Sprintf("jsl: cycle of refs through definition: %s", string(e))
 }
 
 // ErrInvalidType indicates that a "type" had an incorrect value.
@@ -35,7 +59,7 @@ func (e ErrNoSuchDefinition) Error() string {
 type ErrInvalidType string
 
 func (e ErrInvalidType) Error() string {
-	return fmt.Sprintf("jsl: no such type: %s", e)
+	return fmt.Sprintf("jsl: no such type: %s", string(e))
 }
 
 // ErrRepeatedEnumValue indicates than an "enum" repeated a value. Enums must
@@ -43,7 +67,7 @@ func (e ErrInvalidType) Error() string {
 type ErrRepeatedEnumValue string
 
 func (e ErrRepeatedEnumValue) Error() string {
-	return fmt.Sprintf("jsl: repeated enum value: %s", e)
+	return fmt.Sprintf("jsl: repeated enum value: %s", string(e))
 }
 
 // ErrRepeatedProperty indicates that a schema had a "properties" and
@@ -51,7 +75,7 @@ func (e ErrRepeatedEnumValue) Error() string {
 type ErrRepeatedProperty string
 
 func (e ErrRepeatedProperty) Error() string {
-	return fmt.Sprintf("jsl: repeated property in properties and optionalProperties: %s", e)
+	return fmt.Sprintf("jsl: repeated property in properties and optionalProperties: %s", string(e))
 }
 
 // ErrRepeatedTagInProperties indicates that one of the elements of
@@ -60,5 +84,112 @@ func (e ErrRepeatedProperty) Error() string {
 type ErrRepeatedTagInProperties string
 
 func (e ErrRepeatedTagInProperties) Error() string {
-	return fmt.Sprintf("jsl: discriminator tag repeated in properties or optionalProperties: %s", e)
+	return fmt.Sprintf("jsl: discriminator tag repeated in properties or optionalProperties: %s", string(e))
+}
+
+// ErrUnsupportedType indicates that an instance contained a Go value that
+// cannot be represented as JSON, such as a channel or a function.
+type ErrUnsupportedType string
+
+func (e ErrUnsupportedType) Error() string {
+	return fmt.Sprintf("jsl: unsupported instance type: %s", string(e))
+}
+
+// ErrInvalidKind indicates that a Kind was being converted to or from text,
+// but was not one of the Kind constants.
+type ErrInvalidKind string
+
+func (e ErrInvalidKind) Error() string {
+	return fmt.Sprintf("jsl: no such kind: %s", string(e))
+}
+
+// ErrUnknownKeyword indicates that a schema being parsed by ParseSchema had a
+// keyword that is not part of JSL, such as a misspelling of a correct keyword.
+type ErrUnknownKeyword string
+
+func (e ErrUnknownKeyword) Error() string {
+	return fmt.Sprintf("jsl: unknown keyword: %s", string(e))
+}
+
+// ErrWrongJSONType indicates that a schema being parsed by ParseSchema had a
+// keyword whose value was of the wrong JSON type. The value of the error is the
+// JSON type that was expected, such as "string" or "object".
+type ErrWrongJSONType string
+
+func (e ErrWrongJSONType) Error() string {
+	return fmt.Sprintf("jsl: wrong JSON type, expected: %s", string(e))
+}
+
+// ErrRepeatedKey indicates that a schema being parsed by ParseSchema had an
+// object that repeated a key. Only one of the values would otherwise be kept.
+type ErrRepeatedKey string
+
+func (e ErrRepeatedKey) Error() string {
+	return fmt.Sprintf("jsl: repeated key in object: %s", string(e))
+}
+
+// SchemaError is a problem with the correctness of a schema, and where in the
+// schema it was found. See VerifyAll.
+type SchemaError struct {
+	// Path is the tokens of a JSON Pointer to the part of the schema which has
+	// the problem.
+	Path []string
+
+	// Err is the problem. It is one of the errors that Verify may return.
+	Err error
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("%s (at %q)", e.Err, pointer(e.Path))
+}
+
+// Unwrap returns Err, so that SchemaError works with errors.Is and errors.As.
+func (e *SchemaError) Unwrap() error {
+	return e.Err
+}
+
+// ParseError is a problem encountered by ParseSchema, and where in the input it
+// was found.
+type ParseError struct {
+	// Line and Column are the position in the input of the problem. Both start
+	// at one. Columns are counted in characters, not bytes.
+	Line   int
+	Column int
+
+	// Path is the tokens of a JSON Pointer to the part of the schema which has
+	// the problem. It is empty if the input is not valid JSON.
+	Path []string
+
+	// Err is the problem. It is a *json.SyntaxError if the input is not valid
+	// JSON.
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s (at %q, line %d, column %d)", e.Err, pointer(e.Path), e.Line, e.Column)
+}
+
+// Unwrap returns Err, so that ParseError works with errors.Is and errors.As.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// LineError is a problem with a line of newline-delimited JSON being validated
+// by NDJSONValidator, other than the line not being valid against the schema.
+type LineError struct {
+	// Line is the number of the line, starting at one.
+	Line int
+
+	// Err is the problem, such as a *json.SyntaxError if the line is not valid
+	// JSON, or ErrMaxDepthExceeded.
+	Err error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Err)
+}
+
+// Unwrap returns Err, so that LineError works with errors.Is and errors.As.
+func (e *LineError) Unwrap() error {
+	return e.Err
 }
@@ -1,8 +1,13 @@
 module github.com/json-schema-language/json-schema-language-go
 
-go 1.12
+go 1.18
 
 require (
 	github.com/dolmen-go/jsonptr v0.0.0-20190605225012-a9a7ae01cd7d
 	github.com/stretchr/testify v1.3.0
 )
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+)
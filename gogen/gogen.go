@@ -0,0 +1,576 @@
+// Package gogen generates Go types from JSON Schema Language schemas.
+//
+// The generated types can be used with encoding/json to decode and encode
+// instances of the schema. Every schema that needs a name of its own in Go
+// becomes a type declaration:
+//
+// The root schema and each definition become a type named after RootName and
+// the name of the definition.
+//
+// Schemas of the properties form become structs. Required properties become
+// fields, and optional properties become fields with omitempty. Optional
+// properties are pointers, unless they are already nillable (slices, maps and
+// interfaces).
+//
+// Schemas of the enum form become a string type, and a constant for each
+// value.
+//
+// Schemas of the discriminator form become a struct holding a sealed
+// interface, which is implemented by a struct for each value of the mapping.
+// The struct has MarshalJSON and UnmarshalJSON methods that use the tag to
+// pick the right implementation.
+//
+// Schemas of the elements and values form become slices and maps, and schemas
+// of the type form become the corresponding Go type. Definitions of the
+// elements and values form are declared as defined types, and definitions of
+// other forms as aliases. "timestamp" becomes
+// time.Time, and "number" becomes float64. The empty form becomes
+// interface{}.
+//
+// Nullable schemas become pointers, unless their type is already nillable.
+// Nullable definitions that become a struct or an enum type are referred to
+// by pointer, but the root schema is always declared as-is.
+//
+// Schemas nested within others which need a name are named after the schema
+// containing them. For instance, the schema of property "address" in the
+// definition "user" is named UserAddress.
+package gogen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	jsl "github.com/json-schema-language/json-schema-language-go"
+)
+
+// Generator generates Go source code from a schema.
+type Generator struct {
+	// Package is the name of the package of the generated code. If empty,
+	// "main" is used.
+	Package string
+
+	// RootName is the name of the type generated for the root schema. If empty,
+	// "Root" is used.
+	RootName string
+
+	// Command is the name of the command generating the code, which is
+	// mentioned in the header of the generated file. If empty, "gogen" is used.
+	Command string
+}
+
+// Generate returns Go source code, formatted by gofmt, for the types of a
+// schema and its definitions.
+//
+// Generate returns the same errors as Verify if the schema is not correct. It
+// also returns an error if two types, fields or constants would end up with
+// the same name, or if a property name cannot be used in a struct tag.
+func (g *Generator) Generate(schema jsl.Schema) ([]byte, error) {
+	if err := schema.Verify(); err != nil {
+		return nil, err
+	}
+
+	s := state{
+		defs:         make(map[string]string, len(schema.Definitions)),
+		definitions:  schema.Definitions,
+		nillableDefs: map[string]bool{},
+		names:        map[string]string{},
+		imports:      map[string]struct{}{},
+		decls:        &bytes.Buffer{},
+	}
+
+	rootName := g.RootName
+	if rootName == "" {
+		rootName = "Root"
+	}
+
+	if err := s.claim(rootName, nil); err != nil {
+		return nil, err
+	}
+
+	defNames := make([]string, 0, len(schema.Definitions))
+	for name := range schema.Definitions {
+		defNames = append(defNames, name)
+	}
+
+	sort.Strings(defNames)
+	for _, name := range defNames {
+		s.defs[name] = goName(name)
+		if err := s.claim(s.defs[name], []string{"definitions", name}); err != nil {
+			return nil, err
+		}
+
+		def := schema.Definitions[name]
+		s.nillableDefs[s.defs[name]] = def.Nullable && !declares(&def)
+	}
+
+	if err := s.declare(rootName, nil, &schema); err != nil {
+		return nil, err
+	}
+
+	for _, name := range defNames {
+		def := schema.Definitions[name]
+		if err := s.declare(s.defs[name], []string{"definitions", name}, &def); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.variants {
+		s.imports["encoding/json"] = struct{}{}
+		s.imports["fmt"] = struct{}{}
+		s.decls.WriteString(variantHelpers)
+	}
+
+	command := g.Command
+	if command == "" {
+		command = "gogen"
+	}
+
+	pkg := g.Package
+	if pkg == "" {
+		pkg = "main"
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by %s. DO NOT EDIT.\n\npackage %s\n", command, pkg)
+
+	if len(s.imports) > 0 {
+		imports := make([]string, 0, len(s.imports))
+		for path := range s.imports {
+			imports = append(imports, strconv.Quote(path))
+		}
+
+		sort.Strings(imports)
+		fmt.Fprintf(&out, "\nimport (\n%s\n)\n", strings.Join(imports, "\n"))
+	}
+
+	out.Write(s.decls.Bytes())
+	return format.Source(out.Bytes())
+}
+
+// state is the state of a single call to Generate.
+type state struct {
+	// defs maps the names of definitions to the names of their types.
+	defs map[string]string
+
+	// definitions are the definitions of the root schema.
+	definitions map[string]jsl.Schema
+
+	// nillableDefs has the names of the types of nullable definitions that are
+	// aliases, and so are nillable without looking it.
+	nillableDefs map[string]bool
+
+	// names maps the names of types and constants declared so far to the path
+	// of the schema they were declared for.
+	names map[string]string
+
+	imports  map[string]struct{}
+	decls    *bytes.Buffer
+	variants bool
+}
+
+// claim reserves a type name for the schema at path.
+func (s *state) claim(name string, path []string) error {
+	if other, ok := s.names[name]; ok {
+		return fmt.Errorf("gogen: %s and %s would both be named %s", describePath(other), describePath(pointer(path)), name)
+	}
+
+	s.names[name] = pointer(path)
+	return nil
+}
+
+// declare writes out a type declaration named name for the schema at path. The
+// name must already have been claimed.
+//
+// Declarations of schemas nested within the schema are written out first.
+func (s *state) declare(name string, path []string, schema *jsl.Schema) error {
+	switch schema.Form() {
+	case jsl.FormEnum:
+		return s.declareEnum(name, path, schema)
+	case jsl.FormProperties:
+		return s.declareStruct(name, path, schema, "")
+	case jsl.FormDiscriminator:
+		return s.declareDiscriminator(name, path, schema)
+	}
+
+	outer := s.nest()
+	expr, err := s.typeExpr(name, path, schema)
+	if err != nil {
+		return err
+	}
+
+	// An alias, rather than a defined type, keeps the methods of the aliased
+	// type, such as the MarshalJSON of time.Time. Slices and maps have no
+	// methods to keep, and become defined types, since they may refer back to
+	// themselves by way of a ref, which an alias may not.
+	nested := s.unnest(outer)
+	s.comment(name, path)
+	if strings.HasPrefix(expr, "[]") || strings.HasPrefix(expr, "map[") {
+		fmt.Fprintf(s.decls, "type %s %s\n", name, expr)
+	} else {
+		fmt.Fprintf(s.decls, "type %s = %s\n", name, expr)
+	}
+
+	s.decls.Write(nested.Bytes())
+	return nil
+}
+
+// nest starts collecting declarations separately, so that they can be written
+// out after the declaration that contains them. The declarations made so far
+// are returned, and must be restored by unnest.
+func (s *state) nest() *bytes.Buffer {
+	outer := s.decls
+	s.decls = &bytes.Buffer{}
+	return outer
+}
+
+// unnest undoes nest, and returns the declarations collected since.
+func (s *state) unnest(outer *bytes.Buffer) *bytes.Buffer {
+	nested := s.decls
+	s.decls = outer
+	return nested
+}
+
+func (s *state) comment(name string, path []string) {
+	fmt.Fprintf(s.decls, "\n// %s is generated from %s.\n", name, describePath(pointer(path)))
+}
+
+// typeExpr returns the Go type for the schema at path. If the schema needs a
+// type declaration of its own, one is made named name.
+//
+// If the schema is nullable, the type is a pointer, unless it is already
+// nillable.
+func (s *state) typeExpr(name string, path []string, schema *jsl.Schema) (string, error) {
+	expr, err := s.nonNullTypeExpr(name, path, schema)
+	if err == nil && schema.Nullable && !s.nillable(expr) {
+		expr = "*" + expr
+	}
+
+	return expr, err
+}
+
+// nonNullTypeExpr is like typeExpr, but ignores whether the schema itself is
+// nullable.
+func (s *state) nonNullTypeExpr(name string, path []string, schema *jsl.Schema) (string, error) {
+	switch schema.Form() {
+	case jsl.FormEmpty:
+		return "interface{}", nil
+	case jsl.FormRef:
+		if def := s.definitions[*schema.Ref]; def.Nullable && declares(&def) {
+			return "*" + s.defs[*schema.Ref], nil
+		}
+
+		return s.defs[*schema.Ref], nil
+	case jsl.FormType:
+		return s.scalar(schema.Type), nil
+	case jsl.FormElements:
+		expr, err := s.typeExpr(name+"Element", extend(path, "elements"), schema.Elements)
+		return "[]" + expr, err
+	case jsl.FormValues:
+		expr, err := s.typeExpr(name+"Value", extend(path, "values"), schema.Values)
+		return "map[string]" + expr, err
+	}
+
+	if err := s.claim(name, path); err != nil {
+		return "", err
+	}
+
+	return name, s.declare(name, path, schema)
+}
+
+func (s *state) scalar(typ jsl.Type) string {
+	switch typ {
+	case jsl.TypeBoolean:
+		return "bool"
+	case jsl.TypeNumber, jsl.TypeFloat64:
+		return "float64"
+	case jsl.TypeString:
+		return "string"
+	case jsl.TypeTimestamp:
+		s.imports["time"] = struct{}{}
+		return "time.Time"
+	default:
+		// The remaining types are named the same in Go and JSL.
+		return string(typ)
+	}
+}
+
+func (s *state) declareEnum(name string, path []string, schema *jsl.Schema) error {
+	s.comment(name, path)
+	fmt.Fprintf(s.decls, "type %s string\n\nconst (\n", name)
+
+	for i, val := range schema.Enum {
+		c := name + goName(val)
+		if err := s.claim(c, extend(path, "enum", strconv.Itoa(i))); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(s.decls, "%s %s = %s\n", c, name, strconv.Quote(val))
+	}
+
+	s.decls.WriteString(")\n")
+	return nil
+}
+
+// declareStruct declares a struct for a schema of the properties form. If the
+// schema is a value of the mapping of a discriminator, variantOf is the name of
+// the type declared for the discriminator.
+func (s *state) declareStruct(name string, path []string, schema *jsl.Schema, variantOf string) error {
+	type field struct {
+		name, typ, tag string
+	}
+
+	outer := s.nest()
+
+	var fields []field
+	for _, k := range sortedKeys(schema.RequiredProperties) {
+		sub := schema.RequiredProperties[k]
+		fields = append(fields, field{name: k, tag: k})
+
+		typ, err := s.typeExpr(name+goName(k), extend(path, "properties", k), &sub)
+		if err != nil {
+			return err
+		}
+
+		fields[len(fields)-1].typ = typ
+	}
+
+	for _, k := range sortedKeys(schema.OptionalProperties) {
+		sub := schema.OptionalProperties[k]
+		fields = append(fields, field{name: k, tag: k + ",omitempty"})
+
+		typ, err := s.typeExpr(name+goName(k), extend(path, "optionalProperties", k), &sub)
+		if err != nil {
+			return err
+		}
+
+		if !s.nillable(typ) {
+			typ = "*" + typ
+		}
+
+		fields[len(fields)-1].typ = typ
+	}
+
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].name < fields[j].name
+	})
+
+	var body bytes.Buffer
+	goNames := map[string]string{}
+	for _, f := range fields {
+		if !validTag(f.name) {
+			return fmt.Errorf("gogen: property %q of %s cannot be used in a struct tag", f.name, describePath(pointer(path)))
+		}
+
+		goName := goName(f.name)
+		if other, ok := goNames[goName]; ok {
+			return fmt.Errorf("gogen: properties %q and %q of %s would both be named %s", other, f.name, describePath(pointer(path)), goName)
+		}
+
+		goNames[goName] = f.name
+		fmt.Fprintf(&body, "%s %s `json:%s`\n", goName, f.typ, strconv.Quote(f.tag))
+	}
+
+	nested := s.unnest(outer)
+	s.comment(name, path)
+	fmt.Fprintf(s.decls, "type %s struct {\n%s}\n", name, body.Bytes())
+
+	if variantOf != "" {
+		fmt.Fprintf(s.decls, "\nfunc (%s) is%s() {}\n", name, variantOf)
+	}
+
+	s.decls.Write(nested.Bytes())
+
+	return nil
+}
+
+func (s *state) declareDiscriminator(name string, path []string, schema *jsl.Schema) error {
+	tag := strconv.Quote(schema.Discriminator.Tag)
+	keys := sortedKeys(schema.Discriminator.Mapping)
+
+	variants := make([]string, len(keys))
+	for i, k := range keys {
+		variants[i] = name + goName(k)
+		if err := s.claim(variants[i], extend(path, "discriminator", "mapping", k)); err != nil {
+			return err
+		}
+	}
+
+	if err := s.claim(name+"Variant", path); err != nil {
+		return err
+	}
+
+	s.comment(name, path)
+	fmt.Fprintf(s.decls, "//\n// Variant is one of %s, according to the %s property.\n", strings.Join(variants, ", "), tag)
+	fmt.Fprintf(s.decls, "type %s struct {\n\tVariant %sVariant\n}\n\n", name, name)
+	fmt.Fprintf(s.decls, "// %sVariant is implemented by the variants of %s.\n", name, name)
+	fmt.Fprintf(s.decls, "type %sVariant interface {\n\tis%s()\n}\n\n", name, name)
+
+	fmt.Fprintf(s.decls, "func (v %s) MarshalJSON() ([]byte, error) {\n\tswitch variant := v.Variant.(type) {\n", name)
+	for i, k := range keys {
+		fmt.Fprintf(s.decls, "case %s:\n\treturn jslMarshalVariant(%s, %s, variant)\n", variants[i], tag, strconv.Quote(k))
+	}
+	fmt.Fprintf(s.decls, "}\n\n\treturn nil, fmt.Errorf(\"%s: unsupported variant: %%T\", v.Variant)\n}\n\n", name)
+
+	fmt.Fprintf(s.decls, "func (v *%s) UnmarshalJSON(data []byte) error {\n", name)
+	fmt.Fprintf(s.decls, "\ttag, err := jslUnmarshalTag(data, %s)\n\tif err != nil {\n\t\treturn err\n\t}\n\n\tswitch tag {\n", tag)
+	for i, k := range keys {
+		fmt.Fprintf(s.decls, "case %s:\n\tvar variant %s\n\terr = json.Unmarshal(data, &variant)\n\tv.Variant = variant\n", strconv.Quote(k), variants[i])
+	}
+	fmt.Fprintf(s.decls, "default:\n\terr = fmt.Errorf(\"%s: unknown %%s: %%q\", %s, tag)\n}\n\n\treturn err\n}\n", name, tag)
+
+	s.variants = true
+
+	for i, k := range keys {
+		m := schema.Discriminator.Mapping[k]
+		if err := s.declareStruct(variants[i], extend(path, "discriminator", "mapping", k), &m, name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// variantHelpers are written out once if there are any discriminators.
+const variantHelpers = `
+// jslMarshalVariant encodes variant, which must encode to an object, with the
+// discriminator tag added to it.
+func jslMarshalVariant(tag, value string, variant interface{}) ([]byte, error) {
+	data, err := json.Marshal(variant)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix, err := json.Marshal(map[string]string{tag: value})
+	if err != nil {
+		return nil, err
+	}
+
+	if string(data) == "{}" {
+		return prefix, nil
+	}
+
+	return append(append(prefix[:len(prefix)-1], ','), data[1:]...), nil
+}
+
+// jslUnmarshalTag returns the value of the discriminator tag of an object.
+func jslUnmarshalTag(data []byte, tag string) (string, error) {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return "", err
+	}
+
+	raw, ok := obj[tag]
+	if !ok {
+		return "", fmt.Errorf("missing discriminator tag: %s", tag)
+	}
+
+	var value string
+	err := json.Unmarshal(raw, &value)
+	return value, err
+}
+`
+
+// nillable returns whether nil is a value of the Go type expr, and so can be
+// used to represent null or an absent optional property.
+func (s *state) nillable(expr string) bool {
+	return expr == "interface{}" || strings.HasPrefix(expr, "*") || strings.HasPrefix(expr, "[]") || strings.HasPrefix(expr, "map[") || s.nillableDefs[expr]
+}
+
+// declares returns whether a schema becomes a type of its own, rather than an
+// alias.
+func declares(schema *jsl.Schema) bool {
+	switch schema.Form() {
+	case jsl.FormEnum, jsl.FormProperties, jsl.FormDiscriminator:
+		return true
+	}
+
+	return false
+}
+
+// goName turns a JSON name into an exported Go identifier. Letters and digits
+// are kept, and every other character starts a new word, which is capitalized.
+func goName(name string) string {
+	var b strings.Builder
+	upper := true
+
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+
+		if b.Len() == 0 && unicode.IsDigit(r) {
+			b.WriteByte('X')
+		}
+
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+
+		b.WriteRune(r)
+	}
+
+	if b.Len() == 0 {
+		return "Empty"
+	}
+
+	return b.String()
+}
+
+// validTag returns whether name can be used as the name in a json struct tag.
+// This mirrors the rules of encoding/json.
+func validTag(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for _, r := range name {
+		switch {
+		case strings.ContainsRune("!#$%&()*+-./:;<=>?@[]^_{|}~ ", r):
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			return false
+		}
+	}
+
+	return true
+}
+
+func sortedKeys(schemas map[string]jsl.Schema) []string {
+	keys := make([]string, 0, len(schemas))
+	for k := range schemas {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+func extend(path []string, tokens ...string) []string {
+	return append(append([]string{}, path...), tokens...)
+}
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+func pointer(tokens []string) string {
+	var b strings.Builder
+	for _, token := range tokens {
+		b.WriteByte('/')
+		b.WriteString(pointerEscaper.Replace(token))
+	}
+
+	return b.String()
+}
+
+func describePath(ptr string) string {
+	if ptr == "" {
+		return "the root schema"
+	}
+
+	return ptr
+}
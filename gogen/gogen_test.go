@@ -0,0 +1,291 @@
+package gogen_test
+
+import (
+	"encoding/json"
+	"flag"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"testing"
+
+	jsl "github.com/json-schema-language/json-schema-language-go"
+	"github.com/json-schema-language/json-schema-language-go/gogen"
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+func TestGenerate(t *testing.T) {
+	var schema jsl.Schema
+	err := json.Unmarshal([]byte(`{
+		"definitions": {
+			"user_address": {
+				"properties": {
+					"street": { "type": "string" },
+					"zip": { "type": "uint32" }
+				}
+			},
+			"event": {
+				"discriminator": {
+					"tag": "type",
+					"mapping": {
+						"created": {
+							"properties": { "at": { "type": "timestamp" } }
+						},
+						"renamed": {
+							"properties": { "name": { "type": "string" } },
+							"optionalProperties": { "reason": { "type": "string" } }
+						}
+					}
+				}
+			}
+		},
+		"properties": {
+			"name": { "type": "string" },
+			"status": { "enum": ["active", "on-hold"] },
+			"addresses": { "elements": { "ref": "user_address" } },
+			"events": { "values": { "ref": "event" } }
+		},
+		"optionalProperties": {
+			"age": { "type": "uint8" },
+			"tags": { "elements": { "type": "string" } },
+			"extra": {},
+			"manager": {
+				"properties": { "id": { "type": "int64" } }
+			}
+		}
+	}`), &schema)
+	assert.NoError(t, err)
+
+	g := gogen.Generator{Package: "model", RootName: "User"}
+	out, err := g.Generate(schema)
+	assert.NoError(t, err)
+
+	if *update {
+		assert.NoError(t, ioutil.WriteFile("testdata/user.golden", out, 0644))
+	}
+
+	golden, err := ioutil.ReadFile("testdata/user.golden")
+	assert.NoError(t, err)
+	assert.Equal(t, string(golden), string(out))
+
+	typeCheck(t, out)
+}
+
+func TestGenerateScalars(t *testing.T) {
+	var schema jsl.Schema
+	err := json.Unmarshal([]byte(`{
+		"definitions": {
+			"b": { "type": "boolean" },
+			"n": { "type": "number" },
+			"f32": { "type": "float32" },
+			"i16": { "type": "int16" },
+			"t": { "type": "timestamp" },
+			"any": {},
+			"r": { "ref": "b" },
+			"list": { "elements": { "elements": { "properties": {} } } }
+		},
+		"values": { "ref": "list" }
+	}`), &schema)
+	assert.NoError(t, err)
+
+	g := gogen.Generator{}
+	out, err := g.Generate(schema)
+	assert.NoError(t, err)
+
+	pkg := typeCheck(t, out)
+	assert.Equal(t, "main", pkg.Name())
+
+	expected := map[string]string{
+		"Root":               "main.Root",
+		"B":                  "bool",
+		"N":                  "float64",
+		"F32":                "float32",
+		"I16":                "int16",
+		"T":                  "time.Time",
+		"Any":                "interface{}",
+		"R":                  "bool",
+		"List":               "main.List",
+		"ListElementElement": "main.ListElementElement",
+	}
+
+	for name, typ := range expected {
+		obj := pkg.Scope().Lookup(name)
+		if assert.NotNil(t, obj, name) {
+			assert.Equal(t, typ, types.Unalias(obj.Type()).String(), name)
+		}
+	}
+
+	// Slices and maps are defined types, rather than aliases.
+	assert.Equal(t, "map[string]main.List", pkg.Scope().Lookup("Root").Type().Underlying().String())
+	assert.Equal(t, "[][]main.ListElementElement", pkg.Scope().Lookup("List").Type().Underlying().String())
+}
+
+func TestGenerateNullable(t *testing.T) {
+	var schema jsl.Schema
+	err := json.Unmarshal([]byte(`{
+		"definitions": {
+			"s": { "type": "string", "nullable": true },
+			"o": { "properties": {}, "nullable": true },
+			"l": { "elements": { "type": "int8", "nullable": true }, "nullable": true },
+			"ro": { "ref": "o" },
+			"rs": { "ref": "s", "nullable": true },
+			"any": { "nullable": true }
+		},
+		"properties": {
+			"s": { "ref": "s" },
+			"o": { "ref": "o", "nullable": true },
+			"e": { "enum": ["a"], "nullable": true },
+			"v": { "values": { "type": "boolean" }, "nullable": true }
+		},
+		"optionalProperties": {
+			"os": { "ref": "s" },
+			"ot": { "type": "timestamp", "nullable": true }
+		}
+	}`), &schema)
+	assert.NoError(t, err)
+
+	g := gogen.Generator{}
+	out, err := g.Generate(schema)
+	assert.NoError(t, err)
+
+	pkg := typeCheck(t, out)
+
+	expected := map[string]string{
+		"S":   "*string",
+		"O":   "main.O",
+		"L":   "main.L",
+		"Ro":  "*main.O",
+		"Rs":  "*string",
+		"Any": "interface{}",
+	}
+
+	for name, typ := range expected {
+		obj := pkg.Scope().Lookup(name)
+		if assert.NotNil(t, obj, name) {
+			assert.Equal(t, typ, types.Unalias(obj.Type()).String(), name)
+		}
+	}
+
+	assert.Equal(t, "[]*int8", pkg.Scope().Lookup("L").Type().Underlying().String())
+
+	fields := map[string]string{
+		"S":  "*string",
+		"O":  "*main.O",
+		"E":  "*main.RootE",
+		"V":  "map[string]bool",
+		"Os": "*string",
+		"Ot": "*time.Time",
+	}
+
+	root := pkg.Scope().Lookup("Root").Type().Underlying().(*types.Struct)
+	assert.Equal(t, len(fields), root.NumFields())
+	for i := 0; i < root.NumFields(); i++ {
+		f := root.Field(i)
+		assert.Equal(t, fields[f.Name()], types.Unalias(f.Type()).String(), f.Name())
+	}
+}
+
+func TestGenerateRecursive(t *testing.T) {
+	var schema jsl.Schema
+	err := json.Unmarshal([]byte(`{
+		"definitions": {
+			"list": { "elements": { "ref": "list" } },
+			"tree": { "values": { "ref": "tree", "nullable": true } },
+			"a": { "elements": { "ref": "b" } },
+			"b": { "values": { "ref": "a" } }
+		},
+		"ref": "list"
+	}`), &schema)
+	assert.NoError(t, err)
+
+	g := gogen.Generator{}
+	out, err := g.Generate(schema)
+	assert.NoError(t, err)
+
+	pkg := typeCheck(t, out)
+
+	expected := map[string]string{
+		"Root": "main.List",
+		"List": "[]main.List",
+		"Tree": "map[string]*main.Tree",
+		"A":    "[]main.B",
+		"B":    "map[string]main.A",
+	}
+
+	for name, want := range expected {
+		obj := pkg.Scope().Lookup(name)
+		if assert.NotNil(t, obj, name) {
+			typ := types.Unalias(obj.Type())
+			if name != "Root" {
+				assert.Equal(t, typ, obj.Type(), name)
+				typ = typ.Underlying()
+			}
+
+			assert.Equal(t, want, typ.String(), name)
+		}
+	}
+}
+
+func TestGenerateInvalid(t *testing.T) {
+	type testCase struct {
+		name string
+		in   string
+		err  string
+	}
+
+	testCases := []testCase{
+		{
+			"incorrect schema",
+			`{"ref":"a"}`,
+			"jsl: no such definition: a",
+		},
+		{
+			"type name collision",
+			`{"definitions":{"root_a":{}},"properties":{"a":{"properties":{}}}}`,
+			"gogen: /definitions/root_a and /properties/a would both be named RootA",
+		},
+		{
+			"field name collision",
+			`{"properties":{"a_b":{}},"optionalProperties":{"aB":{}}}`,
+			`gogen: properties "aB" and "a_b" of the root schema would both be named AB`,
+		},
+		{
+			"enum value collision",
+			`{"enum":["a-b","a_b"]}`,
+			"gogen: /enum/0 and /enum/1 would both be named RootAB",
+		},
+		{
+			"invalid tag",
+			`{"properties":{"a,b":{}}}`,
+			`gogen: property "a,b" of the root schema cannot be used in a struct tag`,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			var schema jsl.Schema
+			assert.NoError(t, json.Unmarshal([]byte(tt.in), &schema))
+
+			g := gogen.Generator{}
+			_, err := g.Generate(schema)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+// typeCheck asserts that src is a correct Go file, and returns its package.
+func typeCheck(t *testing.T, src []byte) *types.Package {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "generated.go", src, 0)
+	assert.NoError(t, err)
+
+	config := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	pkg, err := config.Check(file.Name.Name, fset, []*ast.File{file}, nil)
+	assert.NoError(t, err)
+
+	return pkg
+}
@@ -0,0 +1,554 @@
+package jsl
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"math/big"
+	"sort"
+	"time"
+)
+
+const (
+	defaultMaxEnumValues = 10
+	defaultMaxProperties = 50
+)
+
+// Inferrer builds a schema from example instances. Add instances to it one by
+// one, and then call Schema to get the tightest reasonable schema that accepts
+// all of them.
+//
+// The zero value of Inferrer is ready to use, with default settings.
+type Inferrer struct {
+	// MaxEnumValues is the greatest number of distinct strings for which an
+	// enum is inferred. An enum is only inferred if every value was seen at
+	// least twice on average, so that a handful of unique strings does not
+	// become an enum. If zero, 10 is used. If negative, enums are never
+	// inferred.
+	MaxEnumValues int
+
+	// MaxProperties is the greatest number of distinct keys for which objects
+	// are inferred to be of the properties form. Objects with more keys than
+	// that are taken to be maps with dynamic keys, and become the values form.
+	// If zero, 50 is used.
+	MaxProperties int
+
+	root *shape
+}
+
+// Infer reads a stream of JSON instances from r, such as newline-delimited
+// JSON, and infers a schema from them using an Inferrer with default settings.
+func Infer(r io.Reader) (Schema, error) {
+	var inferrer Inferrer
+
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	for {
+		var instance interface{}
+		if err := dec.Decode(&instance); err == io.EOF {
+			return inferrer.Schema(), nil
+		} else if err != nil {
+			return Schema{}, err
+		}
+
+		if err := inferrer.Add(instance); err != nil {
+			return Schema{}, err
+		}
+	}
+}
+
+// Add records an example instance. Instances may be any Go value that
+// encoding/json can marshal.
+func (i *Inferrer) Add(instance interface{}) error {
+	if i.root == nil {
+		i.root = &shape{}
+	}
+
+	return i.add(i.root, instance)
+}
+
+// shape accumulates what has been seen of the instances at one place in a
+// schema.
+type shape struct {
+	count int
+	nulls int
+
+	bools int
+
+	// numbers are described by their range, and whether they were all
+	// integers.
+	numbers  int
+	integral bool
+	min, max *big.Rat
+
+	// values holds the distinct strings seen, until there are too many for an
+	// enum, at which point it is set to nil and manyValues is set.
+	strings    int
+	timestamps int
+	values     map[string]int
+	manyValues bool
+
+	arrays   int
+	elements *shape
+
+	objects    int
+	properties map[string]*shape
+
+	// partitions groups objects by the value of each of their string
+	// properties that may be a discriminator tag, so that each group can become
+	// a value of the mapping. Shapes within partitions are flat: they do not
+	// have partitions of their own, which keeps the work of adding instances
+	// proportional to their size.
+	flat       bool
+	partitions map[string]map[string]*shape
+}
+
+func (i *Inferrer) add(s *shape, instance interface{}) error {
+	instance, err := normalize(instance)
+	if err != nil {
+		return err
+	}
+
+	s.count++
+
+	switch v := instance.(type) {
+	case nil:
+		s.nulls++
+	case bool:
+		s.bools++
+	case string:
+		s.strings++
+		if _, err := time.Parse(time.RFC3339, v); err == nil {
+			s.timestamps++
+		}
+
+		if !s.manyValues {
+			if s.values == nil {
+				s.values = map[string]int{}
+			}
+
+			s.values[v]++
+			if len(s.values) > i.maxEnumValues() {
+				s.values = nil
+				s.manyValues = true
+			}
+		}
+	case []interface{}:
+		s.arrays++
+		if s.elements == nil {
+			s.elements = &shape{flat: s.flat}
+		}
+
+		for _, elem := range v {
+			if err := i.add(s.elements, elem); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		return i.addObject(s, v)
+	default:
+		s.addNumber(v)
+	}
+
+	return nil
+}
+
+func (i *Inferrer) addObject(s *shape, obj map[string]interface{}) error {
+	if s.properties == nil {
+		s.properties = map[string]*shape{}
+	}
+
+	for k, v := range obj {
+		if _, ok := s.properties[k]; !ok {
+			s.properties[k] = &shape{flat: s.flat}
+		}
+
+		if err := i.add(s.properties[k], v); err != nil {
+			return err
+		}
+	}
+
+	if !s.flat {
+		if err := i.partition(s, obj); err != nil {
+			return err
+		}
+	}
+
+	s.objects++
+	return nil
+}
+
+// partition adds obj to the partitions of s. A property remains a candidate for
+// being a discriminator tag only as long as every object has it, its value is
+// always a string, and it has no more distinct values than an enum may have.
+func (i *Inferrer) partition(s *shape, obj map[string]interface{}) error {
+	if s.objects == 0 {
+		s.partitions = map[string]map[string]*shape{}
+		for k, v := range obj {
+			if _, ok := v.(string); ok {
+				s.partitions[k] = map[string]*shape{}
+			}
+		}
+	}
+
+	for k, groups := range s.partitions {
+		tag, ok := obj[k].(string)
+		if !ok {
+			delete(s.partitions, k)
+			continue
+		}
+
+		group, ok := groups[tag]
+		if !ok {
+			if len(groups) == i.maxTagValues() {
+				delete(s.partitions, k)
+				continue
+			}
+
+			group = &shape{flat: true}
+			groups[tag] = group
+		}
+
+		if err := i.add(group, obj); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *shape) addNumber(v interface{}) {
+	r := new(big.Rat)
+	switch v := v.(type) {
+	case float64:
+		r.SetFloat64(v)
+	case json.Number:
+		r.SetString(string(v))
+	case int64:
+		r.SetInt64(v)
+	case uint64:
+		r.SetInt(new(big.Int).SetUint64(v))
+	}
+
+	if s.numbers == 0 {
+		s.integral = true
+		s.min, s.max = r, r
+	}
+
+	s.numbers++
+	s.integral = s.integral && r.IsInt()
+
+	if r.Cmp(s.min) < 0 {
+		s.min = r
+	}
+
+	if r.Cmp(s.max) > 0 {
+		s.max = r
+	}
+}
+
+// Schema returns the schema inferred from the instances added so far. If no
+// instances were added, the schema is of the empty form.
+func (i *Inferrer) Schema() Schema {
+	if i.root == nil {
+		return Schema{}
+	}
+
+	return i.schema(i.root)
+}
+
+func (i *Inferrer) schema(s *shape) Schema {
+	kinds := 0
+	for _, n := range []int{s.bools, s.numbers, s.strings, s.arrays, s.objects} {
+		if n > 0 {
+			kinds++
+		}
+	}
+
+	// Values of differing JSON types can only be described by the empty form,
+	// as can nulls on their own.
+	if kinds != 1 {
+		return Schema{}
+	}
+
+	var out Schema
+	switch {
+	case s.bools > 0:
+		out = Schema{Type: TypeBoolean}
+	case s.numbers > 0:
+		out = Schema{Type: s.numberType()}
+	case s.strings > 0:
+		out = i.stringSchema(s)
+	case s.arrays > 0:
+		elements := Schema{}
+		if s.elements.count > 0 {
+			elements = i.schema(s.elements)
+		}
+
+		out = Schema{Elements: &elements}
+	default:
+		out = i.objectSchema(s)
+	}
+
+	out.Nullable = s.nulls > 0
+	return out
+}
+
+// integerTypes are the integer types, from narrowest to widest, along with
+// their ranges. Unsigned types come before signed types of the same width.
+var integerTypes = []struct {
+	typ      Type
+	min, max *big.Rat
+}{
+	{TypeUint8, big.NewRat(0, 1), big.NewRat(math.MaxUint8, 1)},
+	{TypeInt8, big.NewRat(math.MinInt8, 1), big.NewRat(math.MaxInt8, 1)},
+	{TypeUint16, big.NewRat(0, 1), big.NewRat(math.MaxUint16, 1)},
+	{TypeInt16, big.NewRat(math.MinInt16, 1), big.NewRat(math.MaxInt16, 1)},
+	{TypeUint32, big.NewRat(0, 1), big.NewRat(math.MaxUint32, 1)},
+	{TypeInt32, big.NewRat(math.MinInt32, 1), big.NewRat(math.MaxInt32, 1)},
+	{TypeUint64, big.NewRat(0, 1), new(big.Rat).SetInt(new(big.Int).SetUint64(math.MaxUint64))},
+	{TypeInt64, big.NewRat(math.MinInt64, 1), big.NewRat(math.MaxInt64, 1)},
+}
+
+// numberType returns the narrowest integer type containing every number seen,
+// if they were all integers. Otherwise, it returns float64.
+func (s *shape) numberType() Type {
+	if !s.integral {
+		return TypeFloat64
+	}
+
+	for _, t := range integerTypes {
+		if s.min.Cmp(t.min) >= 0 && s.max.Cmp(t.max) <= 0 {
+			return t.typ
+		}
+	}
+
+	return TypeNumber
+}
+
+func (i *Inferrer) stringSchema(s *shape) Schema {
+	if s.timestamps == s.strings {
+		return Schema{Type: TypeTimestamp}
+	}
+
+	if s.manyValues || i.MaxEnumValues < 0 || len(s.values) > i.maxEnumValues() || s.strings < 2*len(s.values) {
+		return Schema{Type: TypeString}
+	}
+
+	enum := make([]string, 0, len(s.values))
+	for val := range s.values {
+		enum = append(enum, val)
+	}
+
+	sort.Strings(enum)
+	return Schema{Enum: enum}
+}
+
+func (i *Inferrer) objectSchema(s *shape) Schema {
+	// Objects with many keys, or whose keys are mostly different each time,
+	// are maps rather than records.
+	if len(s.properties) > i.maxProperties() || s.dynamicKeys() {
+		values := &shape{}
+		for _, sub := range s.properties {
+			values.merge(sub)
+		}
+
+		valuesSchema := Schema{}
+		if values.count > 0 {
+			valuesSchema = i.schema(values)
+		}
+
+		return Schema{Values: &valuesSchema}
+	}
+
+	if tag, ok := i.discriminator(s); ok {
+		mapping := map[string]Schema{}
+		for val, group := range s.partitions[tag] {
+			mapping[val] = i.propertiesSchema(group, tag)
+		}
+
+		return Schema{Discriminator: Discriminator{Tag: tag, Mapping: mapping}}
+	}
+
+	return i.propertiesSchema(s, "")
+}
+
+// dynamicKeys returns whether the keys of the objects seen look like data
+// rather than field names: there were several objects, with several keys, but
+// no key was in more than half of the objects.
+func (s *shape) dynamicKeys() bool {
+	if s.objects < 2 || len(s.properties) < 2 {
+		return false
+	}
+
+	for _, sub := range s.properties {
+		if 2*sub.count > s.objects {
+			return false
+		}
+	}
+
+	return true
+}
+
+// propertiesSchema returns a schema of the properties form. Properties seen in
+// every object are required, and the others are optional. The property named
+// skip is left out.
+func (i *Inferrer) propertiesSchema(s *shape, skip string) Schema {
+	out := Schema{RequiredProperties: map[string]Schema{}}
+	for k, sub := range s.properties {
+		if k == skip {
+			continue
+		}
+
+		if sub.count == s.objects {
+			out.RequiredProperties[k] = i.schema(sub)
+		} else {
+			if out.OptionalProperties == nil {
+				out.OptionalProperties = map[string]Schema{}
+			}
+
+			out.OptionalProperties[k] = i.schema(sub)
+		}
+	}
+
+	return out
+}
+
+// discriminator returns the property of the objects which best partitions
+// them by their shape, if there is one.
+//
+// Of the properties that may be a tag, the ones that leave no property
+// optional in every group are preferred, and otherwise the one that leaves the
+// fewest. Ties are broken by name. A property is only a tag if partitioning by
+// it leaves fewer optional properties than not doing so, and if it has at
+// least two values, each of which was seen at least twice on average.
+func (i *Inferrer) discriminator(s *shape) (string, bool) {
+	best, bestOptional := "", s.optionalCount()
+
+	candidates := make([]string, 0, len(s.partitions))
+	for k := range s.partitions {
+		candidates = append(candidates, k)
+	}
+
+	sort.Strings(candidates)
+	for _, k := range candidates {
+		groups := s.partitions[k]
+		if len(groups) < 2 || s.objects < 2*len(groups) {
+			continue
+		}
+
+		optional := 0
+		for _, group := range groups {
+			optional += group.optionalCount()
+		}
+
+		if optional < bestOptional {
+			best, bestOptional = k, optional
+		}
+	}
+
+	return best, best != ""
+}
+
+// optionalCount returns the number of properties not present in every object.
+func (s *shape) optionalCount() int {
+	n := 0
+	for _, sub := range s.properties {
+		if sub.count < s.objects {
+			n++
+		}
+	}
+
+	return n
+}
+
+// merge adds everything seen by other to s. The result is flat.
+func (s *shape) merge(other *shape) {
+	s.count += other.count
+	s.nulls += other.nulls
+	s.bools += other.bools
+
+	if other.numbers > 0 {
+		if s.numbers == 0 {
+			s.integral = true
+			s.min, s.max = other.min, other.max
+		}
+
+		s.integral = s.integral && other.integral
+		if other.min.Cmp(s.min) < 0 {
+			s.min = other.min
+		}
+
+		if other.max.Cmp(s.max) > 0 {
+			s.max = other.max
+		}
+
+		s.numbers += other.numbers
+	}
+
+	s.strings += other.strings
+	s.timestamps += other.timestamps
+	s.manyValues = s.manyValues || other.manyValues
+	if !s.manyValues {
+		for val, n := range other.values {
+			if s.values == nil {
+				s.values = map[string]int{}
+			}
+
+			s.values[val] += n
+		}
+	}
+
+	if other.elements != nil {
+		if s.elements == nil {
+			s.elements = &shape{}
+		}
+
+		s.elements.merge(other.elements)
+	}
+
+	s.arrays += other.arrays
+
+	for k, sub := range other.properties {
+		if s.properties == nil {
+			s.properties = map[string]*shape{}
+		}
+
+		if _, ok := s.properties[k]; !ok {
+			s.properties[k] = &shape{}
+		}
+
+		s.properties[k].merge(sub)
+	}
+
+	s.objects += other.objects
+	s.flat = true
+	s.partitions = nil
+}
+
+func (i *Inferrer) maxEnumValues() int {
+	if i.MaxEnumValues == 0 {
+		return defaultMaxEnumValues
+	}
+
+	return i.MaxEnumValues
+}
+
+// maxTagValues is the greatest number of distinct values of a discriminator
+// tag. It is the same as for enums, but applies even if enums are disabled.
+func (i *Inferrer) maxTagValues() int {
+	if i.MaxEnumValues <= 0 {
+		return defaultMaxEnumValues
+	}
+
+	return i.MaxEnumValues
+}
+
+func (i *Inferrer) maxProperties() int {
+	if i.MaxProperties == 0 {
+		return defaultMaxProperties
+	}
+
+	return i.MaxProperties
+}
@@ -0,0 +1,105 @@
+package jsl_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	jsl "github.com/json-schema-language/json-schema-language-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInfer(t *testing.T) {
+	type testCase struct {
+		name string
+		in   string
+		out  string
+	}
+
+	testCases := []testCase{
+		{"no instances", ``, `{}`},
+		{"booleans", `true false`, `{"type":"boolean"}`},
+		{"uint8", `0 255`, `{"type":"uint8"}`},
+		{"int8", `-128 127`, `{"type":"int8"}`},
+		{"uint16", `0 256`, `{"type":"uint16"}`},
+		{"int32", `-1 65536`, `{"type":"int32"}`},
+		{"uint64", `0 18446744073709551615`, `{"type":"uint64"}`},
+		{"int64", `-1 9223372036854775807`, `{"type":"int64"}`},
+		{"big integers", `-1 18446744073709551615`, `{"type":"number"}`},
+		{"floats", `1 1.5`, `{"type":"float64"}`},
+		{"strings", `"a" "b" "c"`, `{"type":"string"}`},
+		{"enum", `"b" "a" "b" "a"`, `{"enum":["a","b"]}`},
+		{"timestamps", `"2019-01-01T00:00:00Z" "2019-06-01T12:00:00+02:00"`, `{"type":"timestamp"}`},
+		{"mixed types", `1 "a"`, `{}`},
+		{"nulls", `null null`, `{}`},
+		{"nullable", `1 null`, `{"type":"uint8","nullable":true}`},
+		{"nullable elements", `[1, null] [null]`, `{"elements":{"type":"uint8","nullable":true}}`},
+		{"empty arrays", `[] []`, `{"elements":{}}`},
+		{"arrays", `[1, 2] [300]`, `{"elements":{"type":"uint16"}}`},
+		{
+			"properties",
+			`{"a": 1, "b": "x"} {"a": 2} {"a": 3, "c": true}`,
+			`{"properties":{"a":{"type":"uint8"}},"optionalProperties":{"b":{"type":"string"},"c":{"type":"boolean"}}}`,
+		},
+		{"empty objects", `{} {}`, `{"properties":{}}`},
+		{
+			"dynamic keys",
+			`{"u1": 1, "u2": 2} {"u3": 3, "u4": 4}`,
+			`{"values":{"type":"uint8"}}`,
+		},
+		{
+			"discriminator",
+			`{"type": "a", "x": 1, "id": "1"}
+			{"type": "b", "y": "s", "id": "2"}
+			{"type": "a", "x": 2, "id": "3"}
+			{"type": "b", "y": "t", "id": "4"}`,
+			`{"discriminator":{"tag":"type","mapping":{` +
+				`"a":{"properties":{"id":{"type":"string"},"x":{"type":"uint8"}}},` +
+				`"b":{"properties":{"id":{"type":"string"},"y":{"type":"string"}}}}}}`,
+		},
+		{
+			"no discriminator for same shapes",
+			`{"kind": "a", "x": 1} {"kind": "b", "x": 2} {"kind": "a", "x": 3} {"kind": "b", "x": 4}`,
+			`{"properties":{"kind":{"enum":["a","b"]},"x":{"type":"uint8"}}}`,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, err := jsl.Infer(strings.NewReader(tt.in))
+			assert.NoError(t, err)
+
+			out, err := json.Marshal(schema)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.out, string(out))
+			assert.NoError(t, schema.Verify())
+
+			// The inferred schema accepts every instance it was inferred from.
+			validator := jsl.Validator{StrictInstanceSemantics: true}
+
+			dec := json.NewDecoder(strings.NewReader(tt.in))
+			for dec.More() {
+				var instance interface{}
+				assert.NoError(t, dec.Decode(&instance))
+
+				result, err := validator.Validate(schema, instance)
+				assert.NoError(t, err)
+				assert.True(t, result.IsValid(), "%v", result.Errors)
+			}
+		})
+	}
+}
+
+func TestInferrer(t *testing.T) {
+	inferrer := jsl.Inferrer{MaxEnumValues: -1, MaxProperties: 2}
+	assert.Equal(t, jsl.Schema{}, inferrer.Schema())
+
+	assert.NoError(t, inferrer.Add(map[string]interface{}{"a": "x", "b": "x", "c": "x"}))
+	assert.NoError(t, inferrer.Add(map[string]interface{}{"a": "x", "b": "x", "c": "x"}))
+	assert.Equal(t, jsl.Schema{Values: &jsl.Schema{Type: jsl.TypeString}}, inferrer.Schema())
+
+	assert.Equal(t, jsl.ErrUnsupportedType("chan int"), inferrer.Add(make(chan int)))
+
+	_, err := jsl.Infer(strings.NewReader(`{} {`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,432 @@
+package jsl
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// normalize converts an instance into one of the types produced by
+// encoding/json when decoding into an interface{}: nil, bool, float64,
+// json.Number, string, []interface{} or map[string]interface{}. Numbers may
+// additionally be int64 or uint64, so that they can be checked exactly.
+//
+// Only the outermost value is converted. Elements of arrays and values of
+// objects are normalized as they are validated.
+//
+// Values that are not of one of those types are converted the same way
+// encoding/json would marshal them: json.Marshaler and encoding.TextMarshaler
+// are honored, as are the "json" tags on struct fields.
+func normalize(instance interface{}) (interface{}, error) {
+	switch v := instance.(type) {
+	case nil, bool, float64, json.Number, string:
+		return instance, nil
+	case []interface{}:
+		// Like every other nil slice or map, a nil one is marshaled as null.
+		if v == nil {
+			return nil, nil
+		}
+
+		return v, nil
+	case map[string]interface{}:
+		if v == nil {
+			return nil, nil
+		}
+
+		return v, nil
+	case int:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	case uint64:
+		return v, nil
+	case json.Marshaler:
+		if val := reflect.ValueOf(instance); val.Kind() == reflect.Ptr && val.IsNil() {
+			return nil, nil
+		}
+
+		data, err := v.MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+
+		var out interface{}
+		if err := dec.Decode(&out); err != nil {
+			return nil, err
+		}
+
+		return out, nil
+	case encoding.TextMarshaler:
+		if val := reflect.ValueOf(instance); val.Kind() == reflect.Ptr && val.IsNil() {
+			return nil, nil
+		}
+
+		text, err := v.MarshalText()
+		if err != nil {
+			return nil, err
+		}
+
+		return string(text), nil
+	}
+
+	val := reflect.ValueOf(instance)
+	switch val.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if val.IsNil() {
+			return nil, nil
+		}
+
+		return normalize(val.Elem().Interface())
+	case reflect.Bool:
+		return val.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return val.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return val.Uint(), nil
+	case reflect.Float32, reflect.Float64:
+		return val.Float(), nil
+	case reflect.String:
+		return val.String(), nil
+	case reflect.Slice:
+		if val.IsNil() {
+			return nil, nil
+		}
+
+		if val.Type().Elem().Kind() == reflect.Uint8 {
+			return base64.StdEncoding.EncodeToString(val.Bytes()), nil
+		}
+
+		return normalizeArray(val), nil
+	case reflect.Array:
+		return normalizeArray(val), nil
+	case reflect.Map:
+		if val.IsNil() {
+			return nil, nil
+		}
+
+		out := make(map[string]interface{}, val.Len())
+		for _, key := range val.MapKeys() {
+			k, err := mapKey(key)
+			if err != nil {
+				return nil, err
+			}
+
+			out[k] = val.MapIndex(key).Interface()
+		}
+
+		return out, nil
+	case reflect.Struct:
+		out := map[string]interface{}{}
+		for _, f := range structFields(val.Type()) {
+			fv, ok := fieldByIndex(val, f.index)
+			if !ok || (f.omitEmpty && isEmptyValue(fv)) {
+				continue
+			}
+
+			if f.quoted {
+				data, err := json.Marshal(fv.Interface())
+				if err != nil {
+					return nil, err
+				}
+
+				out[f.name] = string(data)
+			} else {
+				out[f.name] = fv.Interface()
+			}
+		}
+
+		return out, nil
+	default:
+		return nil, ErrUnsupportedType(val.Type().String())
+	}
+}
+
+func normalizeArray(val reflect.Value) []interface{} {
+	out := make([]interface{}, val.Len())
+	for i := range out {
+		out[i] = val.Index(i).Interface()
+	}
+
+	return out
+}
+
+func mapKey(key reflect.Value) (string, error) {
+	if key.Kind() == reflect.String {
+		return key.String(), nil
+	}
+
+	if tm, ok := key.Interface().(encoding.TextMarshaler); ok {
+		text, err := tm.MarshalText()
+		return string(text), err
+	}
+
+	switch key.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(key.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(key.Uint(), 10), nil
+	}
+
+	return "", ErrUnsupportedType(key.Type().String())
+}
+
+// fieldByIndex is like reflect.Value.FieldByIndex, but reports false instead
+// of panicking when it encounters a nil embedded pointer.
+func fieldByIndex(val reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && val.Kind() == reflect.Ptr {
+			if val.IsNil() {
+				return reflect.Value{}, false
+			}
+
+			val = val.Elem()
+		}
+
+		val = val.Field(x)
+	}
+
+	return val, true
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+
+	return false
+}
+
+// field is a struct field as seen by encoding/json.
+type field struct {
+	name      string
+	index     []int
+	typ       reflect.Type
+	tagged    bool
+	omitEmpty bool
+	quoted    bool
+}
+
+var fieldCache sync.Map // map[reflect.Type][]field
+
+// structFields returns the fields of a struct type that encoding/json would
+// marshal, applying the same rules for "json" tags and embedded structs.
+func structFields(t reflect.Type) []field {
+	if fields, ok := fieldCache.Load(t); ok {
+		return fields.([]field)
+	}
+
+	var fields []field
+	collectFields(t, nil, map[reflect.Type]bool{}, &fields)
+
+	// Of the fields sharing a name, the least nested one wins. Among those
+	// nested equally deep, a tagged field wins. If that does not settle it,
+	// none of them are marshaled.
+	sort.SliceStable(fields, func(i, j int) bool {
+		if fields[i].name != fields[j].name {
+			return fields[i].name < fields[j].name
+		}
+
+		if len(fields[i].index) != len(fields[j].index) {
+			return len(fields[i].index) < len(fields[j].index)
+		}
+
+		return fields[i].tagged && !fields[j].tagged
+	})
+
+	out := fields[:0]
+	for i := 0; i < len(fields); {
+		j := i + 1
+		for j < len(fields) && fields[j].name == fields[i].name {
+			j++
+		}
+
+		dominant := fields[i]
+		if j-i == 1 || len(fields[i+1].index) > len(dominant.index) || (dominant.tagged && !fields[i+1].tagged) {
+			out = append(out, dominant)
+		}
+
+		i = j
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		for k := 0; k < len(out[i].index) && k < len(out[j].index); k++ {
+			if out[i].index[k] != out[j].index[k] {
+				return out[i].index[k] < out[j].index[k]
+			}
+		}
+
+		return len(out[i].index) < len(out[j].index)
+	})
+
+	fieldCache.Store(t, out)
+	return out
+}
+
+func collectFields(t reflect.Type, index []int, visited map[reflect.Type]bool, fields *[]field) {
+	if visited[t] {
+		return
+	}
+
+	visited[t] = true
+	defer delete(visited, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts := tag, ""
+		if comma := strings.Index(tag, ","); comma != -1 {
+			name, opts = tag[:comma], tag[comma:]
+		}
+
+		fieldIndex := make([]int, len(index)+1)
+		copy(fieldIndex, index)
+		fieldIndex[len(index)] = i
+
+		if sf.Anonymous && name == "" {
+			ft := sf.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+
+			if ft.Kind() == reflect.Struct {
+				collectFields(ft, fieldIndex, visited, fields)
+				continue
+			}
+		}
+
+		if sf.PkgPath != "" {
+			// Unexported, and not an embedded struct.
+			continue
+		}
+
+		f := field{
+			name:      name,
+			index:     fieldIndex,
+			typ:       sf.Type,
+			tagged:    name != "",
+			omitEmpty: strings.Contains(opts, ",omitempty"),
+		}
+
+		if f.name == "" {
+			f.name = sf.Name
+		}
+
+		if strings.Contains(opts, ",string") {
+			switch sf.Type.Kind() {
+			case reflect.Bool, reflect.String,
+				reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+				reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+				reflect.Float32, reflect.Float64:
+				f.quoted = true
+			}
+		}
+
+		*fields = append(*fields, f)
+	}
+}
+
+// isNumber reports whether a normalized instance is a number.
+func isNumber(instance interface{}) bool {
+	switch instance.(type) {
+	case float64, json.Number, int64, uint64:
+		return true
+	}
+
+	return false
+}
+
+// maxDescribeLen is the maximum length, in bytes, of the strings returned by
+// describe, not counting the surrounding quotes of a string.
+const maxDescribeLen = 64
+
+// describe renders an instance for use as the Actual of a ValidationError.
+//
+// Scalars are rendered as JSON, with long strings truncated. Arrays and
+// objects are only rendered as "[...]" and "{...}", since their contents are
+// the subject of errors of their own.
+func describe(instance interface{}) string {
+	instance, err := normalize(instance)
+	if err != nil {
+		return "?"
+	}
+
+	switch v := instance.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		// Format the number the same way encoding/json does, unless it is one of
+		// the values that JSON cannot represent.
+		if data, err := json.Marshal(v); err == nil {
+			return string(data)
+		}
+
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case json.Number:
+		return truncate(string(v))
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case string:
+		data, _ := json.Marshal(truncate(v))
+		return string(data)
+	case []interface{}:
+		return "[...]"
+	default:
+		return "{...}"
+	}
+}
+
+// truncate shortens s to at most maxDescribeLen bytes, without cutting a UTF-8
+// sequence in half, and marks it with an ellipsis if it was shortened.
+func truncate(s string) string {
+	if len(s) <= maxDescribeLen {
+		return s
+	}
+
+	i := maxDescribeLen
+	for i > 0 && !utf8.RuneStart(s[i]) {
+		i--
+	}
+
+	return s[:i] + "..."
+}
+
+// objectKeys returns the keys of a normalized object, sorted.
+func objectKeys(obj map[string]interface{}) []string {
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
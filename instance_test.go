@@ -0,0 +1,173 @@
+package jsl_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	jsl "github.com/json-schema-language/json-schema-language-go"
+	"github.com/stretchr/testify/assert"
+)
+
+type address struct {
+	Street string `json:"street"`
+	City   string `json:"city,omitempty"`
+}
+
+type person struct {
+	ID       int64          `json:"id"`
+	Name     string         `json:"name"`
+	Age      uint8          `json:"age"`
+	Phones   []string       `json:"phones"`
+	Scores   map[string]int `json:"scores,omitempty"`
+	Born     time.Time      `json:"born"`
+	Address  *address       `json:"address,omitempty"`
+	Balance  json.Number    `json:"balance"`
+	Secret   string         `json:"-"`
+	Labels   map[int]string `json:"labels,omitempty"`
+	Rating   float32        `json:"rating,string"`
+	internal string
+}
+
+func TestValidateGoValues(t *testing.T) {
+	schema := jsl.Schema{
+		RequiredProperties: map[string]jsl.Schema{
+			"id":      jsl.Schema{Type: jsl.TypeInt64},
+			"name":    jsl.Schema{Type: jsl.TypeString},
+			"age":     jsl.Schema{Type: jsl.TypeUint8},
+			"phones":  jsl.Schema{Elements: &jsl.Schema{Type: jsl.TypeString}},
+			"born":    jsl.Schema{Type: jsl.TypeTimestamp},
+			"balance": jsl.Schema{Type: jsl.TypeNumber},
+			"rating":  jsl.Schema{Type: jsl.TypeString},
+		},
+		OptionalProperties: map[string]jsl.Schema{
+			"scores": jsl.Schema{Values: &jsl.Schema{Type: jsl.TypeInt8}},
+			"labels": jsl.Schema{Values: &jsl.Schema{Type: jsl.TypeString}},
+			"address": jsl.Schema{
+				RequiredProperties: map[string]jsl.Schema{
+					"street": jsl.Schema{Type: jsl.TypeString},
+					"city":   jsl.Schema{Type: jsl.TypeString},
+				},
+			},
+		},
+	}
+
+	validator := jsl.Validator{StrictInstanceSemantics: true}
+
+	result, err := validator.Validate(schema, person{
+		ID:      9007199254740993,
+		Name:    "John Doe",
+		Age:     43,
+		Phones:  []string{"+44 1234567"},
+		Scores:  map[string]int{"a": 1},
+		Born:    time.Now(),
+		Address: &address{Street: "Main St", City: "Springfield"},
+		Balance: "12.5",
+		Labels:  map[int]string{1: "a"},
+		Secret:  "hidden",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []jsl.ValidationError(nil), result.Errors)
+
+	result, err = validator.Validate(schema, &person{
+		Scores:  map[string]int{"a": 1000},
+		Address: &address{},
+	})
+	assert.NoError(t, err)
+	sortValidationErrors(result.Errors)
+	assert.Equal(t, []jsl.ValidationError{
+		jsl.ValidationError{
+			InstancePath: []string{"address"},
+			SchemaPath:   []string{"optionalProperties", "address", "properties", "city"},
+			Kind:         jsl.KindMissingProperty,
+			Expected:     []string{"city"},
+		},
+		jsl.ValidationError{
+			InstancePath: []string{"scores", "a"},
+			SchemaPath:   []string{"optionalProperties", "scores", "values", "type"},
+			Kind:         jsl.KindType,
+			Expected:     []string{"int8"},
+			Actual:       "1000",
+		},
+		jsl.ValidationError{
+			InstancePath: []string{"phones"},
+			SchemaPath:   []string{"properties", "phones", "elements"},
+			Kind:         jsl.KindElements,
+			Expected:     []string{"array"},
+			Actual:       "null",
+		},
+	}, result.Errors)
+}
+
+func TestValidateGoValuesEmbedded(t *testing.T) {
+	type Base struct {
+		ID   int    `json:"id"`
+		Kind string `json:"kind"`
+	}
+
+	type event struct {
+		Base
+		Kind int `json:"kind"`
+	}
+
+	schema := jsl.Schema{
+		RequiredProperties: map[string]jsl.Schema{
+			"id":   jsl.Schema{Type: jsl.TypeUint32},
+			"kind": jsl.Schema{Type: jsl.TypeUint32},
+		},
+	}
+
+	validator := jsl.Validator{StrictInstanceSemantics: true}
+	result, err := validator.Validate(schema, event{Base: Base{ID: 1, Kind: "a"}, Kind: 2})
+	assert.NoError(t, err)
+	assert.True(t, result.IsValid())
+
+	result, err = validator.Validate(schema, event{Base: Base{ID: -1}})
+	assert.NoError(t, err)
+	assert.Equal(t, []jsl.ValidationError{
+		jsl.ValidationError{
+			InstancePath: []string{"id"},
+			SchemaPath:   []string{"properties", "id", "type"},
+			Kind:         jsl.KindType,
+			Expected:     []string{"uint32"},
+			Actual:       "-1",
+		},
+	}, result.Errors)
+}
+
+func TestValidateGoValuesNil(t *testing.T) {
+	// Nil maps and slices are marshaled as null, whatever their type.
+	instances := []interface{}{
+		map[string]interface{}(nil),
+		[]interface{}(nil),
+		map[string]string(nil),
+		[]string(nil),
+		(*address)(nil),
+	}
+
+	validator := jsl.Validator{}
+	for _, instance := range instances {
+		result, err := validator.Validate(jsl.Schema{Type: jsl.TypeString, Nullable: true}, instance)
+		assert.NoError(t, err)
+		assert.True(t, result.IsValid(), "%T", instance)
+
+		result, err = validator.Validate(jsl.Schema{OptionalProperties: map[string]jsl.Schema{}}, instance)
+		assert.NoError(t, err)
+		assert.False(t, result.IsValid(), "%T", instance)
+
+		result, err = validator.Validate(jsl.Schema{Elements: &jsl.Schema{}}, instance)
+		assert.NoError(t, err)
+		assert.False(t, result.IsValid(), "%T", instance)
+	}
+}
+
+func TestValidateGoValuesUnsupported(t *testing.T) {
+	validator := jsl.Validator{}
+	_, err := validator.Validate(jsl.Schema{Type: jsl.TypeString}, make(chan int))
+	assert.Equal(t, jsl.ErrUnsupportedType("chan int"), err)
+
+	// The empty form accepts anything, and so never looks at the instance.
+	result, err := validator.Validate(jsl.Schema{}, make(chan int))
+	assert.NoError(t, err)
+	assert.True(t, result.IsValid())
+}
@@ -0,0 +1,144 @@
+package jsl
+
+import "strings"
+
+// MessageCatalog turns a ValidationError into a sentence describing the
+// problem, such as "expected string, got number".
+//
+// The sentence should not mention InstancePath. Messages and Message prefix it
+// with a JSON Pointer to the problematic part of the instance.
+type MessageCatalog interface {
+	Message(err ValidationError) string
+}
+
+// Catalog is a MessageCatalog built from a template for each Kind.
+//
+// Templates may contain the following placeholders:
+//
+// {expected} is replaced by the Expected of the error, joined by ", ".
+//
+// {actual} is replaced by the Actual of the error.
+//
+// {actualType} is replaced by the JSON type of Actual: one of "null",
+// "boolean", "number", "string", "array" or "object".
+//
+// Words translates the words that may appear in {expected} and {actualType},
+// such as "object" or "number". Words without a translation are used as-is.
+type Catalog struct {
+	Templates map[Kind]string
+	Words     map[string]string
+}
+
+// EnglishCatalog is the default MessageCatalog, which produces messages in
+// English.
+var EnglishCatalog = Catalog{
+	Templates: map[Kind]string{
+		KindType:             "expected {expected}, got {actualType}",
+		KindFloat32Range:     "{actual} is out of range for float32",
+		KindFloat32Precision: "{actual} cannot be represented exactly as a float32",
+		KindEnum:             "expected one of {expected}, got {actual}",
+		KindElements:         "expected {expected}, got {actualType}",
+		KindProperties:       "expected {expected}, got {actualType}",
+		KindMissingProperty:  "missing required property {expected}",
+		KindUnknownProperty:  "unknown property, expected one of {expected}",
+		KindValues:           "expected {expected}, got {actualType}",
+		KindDiscriminator:    "expected {expected}, got {actualType}",
+		KindMissingTag:       "missing discriminator tag {expected}",
+		KindTagType:          "expected discriminator tag to be a {expected}, got {actualType}",
+		KindUnmappedTag:      "expected discriminator tag to be one of {expected}, got {actual}",
+	},
+}
+
+// Message renders an error using the catalog's template for its Kind. If the
+// catalog has no such template, the template from EnglishCatalog is used.
+func (c Catalog) Message(err ValidationError) string {
+	template, ok := c.Templates[err.Kind]
+	if !ok {
+		template, ok = EnglishCatalog.Templates[err.Kind]
+	}
+
+	if !ok {
+		return err.Kind.String()
+	}
+
+	expected := make([]string, len(err.Expected))
+	for i, word := range err.Expected {
+		expected[i] = c.word(word)
+	}
+
+	return strings.NewReplacer(
+		"{expected}", strings.Join(expected, ", "),
+		"{actual}", err.Actual,
+		"{actualType}", c.word(actualType(err.Actual)),
+	).Replace(template)
+}
+
+func (c Catalog) word(word string) string {
+	if translated, ok := c.Words[word]; ok {
+		return translated
+	}
+
+	return word
+}
+
+// actualType returns the JSON type of a value rendered for the Actual of a
+// ValidationError.
+func actualType(actual string) string {
+	switch {
+	case actual == "":
+		return ""
+	case actual == "null":
+		return "null"
+	case actual == "true" || actual == "false":
+		return "boolean"
+	case actual[0] == '"':
+		return "string"
+	case actual[0] == '[':
+		return "array"
+	case actual[0] == '{':
+		return "object"
+	default:
+		return "number"
+	}
+}
+
+// Message renders the error as a human-readable message, like
+// "/phones/1: expected string, got number". A nil catalog is taken to be
+// EnglishCatalog.
+//
+// The message starts with a JSON Pointer to the problematic part of the
+// instance, unless that is the instance itself.
+func (e ValidationError) Message(catalog MessageCatalog) string {
+	if catalog == nil {
+		catalog = EnglishCatalog
+	}
+
+	if len(e.InstancePath) == 0 {
+		return catalog.Message(e)
+	}
+
+	return pointer(e.InstancePath) + ": " + catalog.Message(e)
+}
+
+// Messages renders each of the errors in the result using Message.
+func (r *ValidationResult) Messages(catalog MessageCatalog) []string {
+	messages := make([]string, len(r.Errors))
+	for i, err := range r.Errors {
+		messages[i] = err.Message(catalog)
+	}
+
+	return messages
+}
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// pointer formats tokens as a JSON Pointer.
+func pointer(tokens []string) string {
+	var b strings.Builder
+	for _, token := range tokens {
+		b.WriteByte('/')
+		b.WriteString(pointerEscaper.Replace(token))
+	}
+
+	return b.String()
+}
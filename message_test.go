@@ -0,0 +1,57 @@
+package jsl_test
+
+import (
+	"testing"
+
+	jsl "github.com/json-schema-language/json-schema-language-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessages(t *testing.T) {
+	schema := jsl.Schema{
+		RequiredProperties: map[string]jsl.Schema{
+			"name":   jsl.Schema{Type: jsl.TypeString},
+			"age":    jsl.Schema{Type: jsl.TypeNumber},
+			"status": jsl.Schema{Enum: []string{"active", "inactive"}},
+			"phones": jsl.Schema{
+				Elements: &jsl.Schema{Type: jsl.TypeString},
+			},
+		},
+	}
+
+	validator := jsl.Validator{StrictInstanceSemantics: true}
+	result, err := validator.ValidateBytes(schema, []byte(`{
+		"age": "43",
+		"status": "gone",
+		"phones": ["+44 1234567", 442345678],
+		"a/b": null
+	}`))
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{
+		`/age: expected number, got string`,
+		`missing required property name`,
+		`/phones/1: expected string, got number`,
+		`/status: expected one of active, inactive, got "gone"`,
+		`/a~1b: unknown property, expected one of age, name, phones, status`,
+	}, result.Messages(nil))
+
+	german := jsl.Catalog{
+		Templates: map[jsl.Kind]string{
+			jsl.KindType:            "{expected} erwartet, {actualType} erhalten",
+			jsl.KindMissingProperty: "Pflichtfeld {expected} fehlt",
+		},
+		Words: map[string]string{
+			"number": "Zahl",
+			"string": "Zeichenkette",
+		},
+	}
+
+	assert.Equal(t, []string{
+		`/age: Zahl erwartet, Zeichenkette erhalten`,
+		`Pflichtfeld name fehlt`,
+		`/phones/1: Zeichenkette erwartet, Zahl erhalten`,
+		`/status: expected one of active, inactive, got "gone"`,
+		`/a~1b: unknown property, expected one of age, name, phones, status`,
+	}, result.Messages(german))
+}
@@ -0,0 +1,220 @@
+package jsl
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+// NDJSONValidator validates newline-delimited JSON, where each line is a
+// separate instance, across several goroutines.
+//
+// The zero value validates with the default settings of Validator, using one
+// goroutine per CPU.
+type NDJSONValidator struct {
+	// Validator is the settings to validate each line with. Its MaxErrors
+	// applies to each line separately.
+	Validator Validator
+
+	// Workers is the number of goroutines to validate lines with. Zero
+	// indicates runtime.GOMAXPROCS(0).
+	Workers int
+
+	// Valid and Invalid, if not nil, are where lines that are valid and lines
+	// that are not valid against the schema are written, each followed by a
+	// newline. Lines are written in the order they were read.
+	Valid   io.Writer
+	Invalid io.Writer
+
+	// Errors, if not nil, is called with the errors of each line that is not
+	// valid, in the order the lines were read. The first token of the
+	// InstancePath of each error is the number of the line, starting at one.
+	//
+	// If Errors returns an error, validation stops, and Validate returns that
+	// error.
+	Errors func(errs []ValidationError) error
+
+	// Malformed, if not nil, is called with a *LineError for each line that is
+	// not valid JSON, in the order the lines were read. Its Err is the
+	// *json.SyntaxError describing the problem. Such lines are otherwise
+	// treated like lines that are not valid against the schema.
+	//
+	// If Malformed returns an error, validation stops, and Validate returns
+	// that error.
+	Malformed func(err *LineError) error
+
+	// FailFast makes validation stop at the first line that is not valid JSON,
+	// and Validate return a *LineError for it. By default, such lines are
+	// counted as invalid, and validation continues with the next line.
+	FailFast bool
+}
+
+// NDJSONSummary is the number of lines that were valid and that were not valid
+// against a schema, as found by NDJSONValidator.
+//
+// Blank lines are skipped, and so are not counted.
+type NDJSONSummary struct {
+	Valid   int
+	Invalid int
+}
+
+// Validate reads newline-delimited JSON from r, and validates each line that
+// is not blank against schema. Lines may end with "\n" or "\r\n".
+//
+// Errors from reading r or writing to Valid and Invalid are returned as-is.
+// If validating a line exceeds Validator.MaxDepth, or a line is not valid JSON
+// and FailFast is set, validation stops, and a *LineError is returned. In all
+// cases, the returned summary counts the lines handled before validation
+// stopped.
+func (n *NDJSONValidator) Validate(schema *CompiledSchema, r io.Reader) (NDJSONSummary, error) {
+	workers := n.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	// Lines are handed to the workers through jobs, and to this goroutine
+	// through pending, in the order they were read. Since pending is buffered,
+	// the workers can get ahead of the line whose result is awaited, but only by
+	// so many lines.
+	jobs := make(chan *ndjsonLine)
+	pending := make(chan *ndjsonLine, workers*4)
+	quit := make(chan struct{})
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer close(quit)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(pending)
+		defer close(jobs)
+
+		n.read(r, jobs, pending, quit)
+	}()
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for line := range jobs {
+				if !json.Valid(line.data) {
+					// Let encoding/json describe what is wrong with the line.
+					var instance interface{}
+					line.err = &LineError{Line: line.number, Err: json.Unmarshal(line.data, &instance)}
+					line.malformed = true
+				} else {
+					line.result, line.err = n.Validator.ValidateCompiledBytes(schema, line.data)
+					if line.err != nil {
+						line.err = &LineError{Line: line.number, Err: line.err}
+					}
+				}
+
+				close(line.done)
+			}
+		}()
+	}
+
+	var summary NDJSONSummary
+	for line := range pending {
+		<-line.done
+		if line.err != nil && (!line.malformed || n.FailFast) {
+			return summary, line.err
+		}
+
+		out := n.Valid
+		if line.malformed {
+			summary.Invalid++
+			out = n.Invalid
+
+			if n.Malformed != nil {
+				if err := n.Malformed(line.err.(*LineError)); err != nil {
+					return summary, err
+				}
+			}
+		} else if line.result.IsValid() {
+			summary.Valid++
+		} else {
+			summary.Invalid++
+			out = n.Invalid
+
+			if n.Errors != nil {
+				for i, err := range line.result.Errors {
+					line.result.Errors[i].InstancePath = append([]string{strconv.Itoa(line.number)}, err.InstancePath...)
+				}
+
+				if err := n.Errors(line.result.Errors); err != nil {
+					return summary, err
+				}
+			}
+		}
+
+		if out != nil {
+			if _, err := out.Write(append(line.data, '\n')); err != nil {
+				return summary, err
+			}
+		}
+	}
+
+	return summary, nil
+}
+
+// ndjsonLine is a line being validated by NDJSONValidator. done is closed once
+// result and err are set. malformed is whether err is because the line is not
+// valid JSON.
+type ndjsonLine struct {
+	number    int
+	data      []byte
+	result    ValidationResult
+	err       error
+	malformed bool
+	done      chan struct{}
+}
+
+// read splits r into lines, and sends those that are not blank to both jobs
+// and pending, until r is exhausted or quit is closed. An error reading r is
+// sent to pending as if it came from a line.
+func (n *NDJSONValidator) read(r io.Reader, jobs, pending chan<- *ndjsonLine, quit <-chan struct{}) {
+	br := bufio.NewReader(r)
+
+	for number := 1; ; number++ {
+		data, err := br.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			line := &ndjsonLine{number: number, err: err, done: make(chan struct{})}
+			close(line.done)
+
+			select {
+			case pending <- line:
+			case <-quit:
+			}
+
+			return
+		}
+
+		data = bytes.TrimRight(data, "\r\n")
+		if len(bytes.TrimSpace(data)) > 0 {
+			line := &ndjsonLine{number: number, data: data, done: make(chan struct{})}
+
+			select {
+			case pending <- line:
+			case <-quit:
+				return
+			}
+
+			select {
+			case jobs <- line:
+			case <-quit:
+				return
+			}
+		}
+
+		if err == io.EOF {
+			return
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package jsl_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	jsl "github.com/json-schema-language/json-schema-language-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNDJSONValidator(t *testing.T) {
+	schema, err := jsl.Compile(jsl.Schema{
+		RequiredProperties: map[string]jsl.Schema{
+			"n": jsl.Schema{Type: jsl.TypeUint8},
+		},
+	})
+	assert.NoError(t, err)
+
+	// Every third line is not valid, and every seventh is blank.
+	var input, valid, invalid strings.Builder
+	var expected []jsl.ValidationError
+	for i := 1; i <= 1000; i++ {
+		switch {
+		case i%7 == 0:
+			input.WriteString(" \r\n")
+		case i%3 == 0:
+			line := fmt.Sprintf(`{"n":%d}`, -i)
+			input.WriteString(line + "\r\n")
+			invalid.WriteString(line + "\n")
+			expected = append(expected, jsl.ValidationError{
+				InstancePath: []string{fmt.Sprint(i), "n"},
+				SchemaPath:   []string{"properties", "n", "type"},
+				Kind:         jsl.KindType,
+				Expected:     []string{"uint8"},
+				Actual:       fmt.Sprint(-i),
+			})
+		default:
+			line := fmt.Sprintf(`{"n":%d}`, i%256)
+			input.WriteString(line + "\n")
+			valid.WriteString(line + "\n")
+		}
+	}
+
+	var validOut, invalidOut bytes.Buffer
+	var errs []jsl.ValidationError
+	v := jsl.NDJSONValidator{
+		Workers: 4,
+		Valid:   &validOut,
+		Invalid: &invalidOut,
+		Errors: func(lineErrs []jsl.ValidationError) error {
+			errs = append(errs, lineErrs...)
+			return nil
+		},
+	}
+
+	summary, err := v.Validate(schema, strings.NewReader(strings.TrimSuffix(input.String(), "\n")))
+	assert.NoError(t, err)
+	assert.Equal(t, jsl.NDJSONSummary{Valid: 572, Invalid: 286}, summary)
+	assert.Equal(t, expected, errs)
+	assert.Equal(t, valid.String(), validOut.String())
+	assert.Equal(t, invalid.String(), invalidOut.String())
+}
+
+func TestNDJSONValidatorMalformed(t *testing.T) {
+	schema, err := jsl.Compile(jsl.Schema{Type: jsl.TypeString})
+	assert.NoError(t, err)
+
+	var invalidOut bytes.Buffer
+	var malformed []*jsl.LineError
+	var errs []jsl.ValidationError
+	v := jsl.NDJSONValidator{
+		Workers: 2,
+		Invalid: &invalidOut,
+		Errors: func(lineErrs []jsl.ValidationError) error {
+			errs = append(errs, lineErrs...)
+			return nil
+		},
+		Malformed: func(err *jsl.LineError) error {
+			malformed = append(malformed, err)
+			return nil
+		},
+	}
+
+	input := "\"a\"\n{\"b\":\n1\n\"c\"\n"
+	summary, err := v.Validate(schema, strings.NewReader(input))
+	assert.NoError(t, err)
+	assert.Equal(t, jsl.NDJSONSummary{Valid: 2, Invalid: 2}, summary)
+	assert.Equal(t, "{\"b\":\n1\n", invalidOut.String())
+
+	if assert.Len(t, malformed, 1) {
+		assert.Equal(t, 2, malformed[0].Line)
+		assert.IsType(t, &json.SyntaxError{}, malformed[0].Err)
+	}
+
+	if assert.Len(t, errs, 1) {
+		assert.Equal(t, []string{"3"}, errs[0].InstancePath)
+	}
+}
+
+func TestNDJSONValidatorFailFast(t *testing.T) {
+	schema, err := jsl.Compile(jsl.Schema{})
+	assert.NoError(t, err)
+
+	input := "{}\n[]\n\n{\n{}\n"
+	summary, err := (&jsl.NDJSONValidator{FailFast: true}).Validate(schema, strings.NewReader(input))
+
+	var lineErr *jsl.LineError
+	assert.True(t, errors.As(err, &lineErr))
+	assert.Equal(t, 4, lineErr.Line)
+	assert.IsType(t, &json.SyntaxError{}, lineErr.Err)
+	assert.Equal(t, jsl.NDJSONSummary{Valid: 2}, summary)
+}
+
+func TestNDJSONValidatorStop(t *testing.T) {
+	schema, err := jsl.Compile(jsl.Schema{Type: jsl.TypeString})
+	assert.NoError(t, err)
+
+	stop := errors.New("stop")
+	calls := 0
+	v := jsl.NDJSONValidator{
+		Workers: 2,
+		Errors: func(errs []jsl.ValidationError) error {
+			calls++
+			return stop
+		},
+	}
+
+	summary, err := v.Validate(schema, strings.NewReader(strings.Repeat("1\n", 100)))
+	assert.Equal(t, stop, err)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, jsl.NDJSONSummary{Invalid: 1}, summary)
+}
+
+func TestNDJSONValidatorMaxDepth(t *testing.T) {
+	schema, err := jsl.Compile(jsl.Schema{
+		Definitions: map[string]jsl.Schema{"a": jsl.Schema{Elements: &jsl.Schema{Ref: strptr("a")}}},
+		Ref:         strptr("a"),
+	})
+	assert.NoError(t, err)
+
+	v := jsl.NDJSONValidator{Validator: jsl.Validator{MaxDepth: 2}}
+	summary, err := v.Validate(schema, strings.NewReader("[]\n[[[]]]\n[]\n"))
+
+	var lineErr *jsl.LineError
+	assert.True(t, errors.As(err, &lineErr))
+	assert.Equal(t, 2, lineErr.Line)
+	assert.Equal(t, jsl.ErrMaxDepthExceeded, lineErr.Err)
+	assert.Equal(t, jsl.NDJSONSummary{Valid: 1}, summary)
+}
@@ -0,0 +1,441 @@
+package jsl
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ParseSchema parses a schema from JSON, more strictly than json.Unmarshal
+// does.
+//
+// json.Unmarshal ignores keys it does not recognize, so that a misspelled
+// keyword such as "optionalProperty" silently turns a schema into one that
+// accepts more than intended. ParseSchema instead rejects unknown keywords,
+// "definitions" anywhere other than the root, repeated keys, and keywords whose
+// values are of the wrong JSON type.
+//
+// Errors are returned as a *ParseError, which contains the line and column of
+// the problem in data, and the path to it within the schema. ParseSchema does
+// not check that the schema is correct; use Verify for that.
+func ParseSchema(data []byte) (Schema, error) {
+	return parse(data, false)
+}
+
+// ParseRFC8927 is like ParseSchema, but parses a schema written as a RFC 8927
+// (JSON Type Definition) document.
+//
+// RFC 8927 has the same keywords as JSL, except that the discriminator form is
+// written as a "discriminator" keyword with the tag, and a separate "mapping"
+// keyword, rather than as an object holding both. ParseRFC8927 does not check
+// that the schema is correct; use VerifyRFC8927 for that.
+func ParseRFC8927(data []byte) (Schema, error) {
+	return parse(data, true)
+}
+
+func parse(data []byte, rfc8927 bool) (Schema, error) {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		offset := len(data)
+		if err, ok := err.(*json.SyntaxError); ok && err.Offset > 0 {
+			offset = int(err.Offset) - 1
+		}
+
+		line, column := position(data, offset)
+		return Schema{}, &ParseError{Line: line, Column: column, Err: err}
+	}
+
+	p := parser{data: data, rfc8927: rfc8927}
+	return p.schema(p.value(), true)
+}
+
+// jsonValue is a JSON value, along with where in the input it came from.
+type jsonValue struct {
+	offset int
+
+	// typ is the JSON type of the value, such as "string" or "object".
+	typ string
+
+	// str and boolean are the values of a string and a boolean. For a number,
+	// str is its text. elements and members are the contents of an array and
+	// an object.
+	str      string
+	boolean  bool
+	elements []jsonValue
+	members  []jsonMember
+}
+
+// jsonMember is a key and value in a JSON object.
+type jsonMember struct {
+	offset int
+	key    string
+	value  jsonValue
+}
+
+// parser turns JSON into a jsonValue, and a jsonValue into a Schema. It only
+// handles input that has already been checked to be valid JSON. rfc8927 is
+// whether the input is written in the syntax of RFC 8927.
+type parser struct {
+	data    []byte
+	rfc8927 bool
+	pos     int
+	path    []string
+}
+
+func (p *parser) value() jsonValue {
+	p.skipSpace()
+
+	v := jsonValue{offset: p.pos}
+	switch p.data[p.pos] {
+	case '{':
+		v.typ = "object"
+		p.pos++
+
+		for p.skipSpace(); p.data[p.pos] != '}'; p.skipSpace() {
+			if p.data[p.pos] == ',' {
+				p.pos++
+				p.skipSpace()
+			}
+
+			m := jsonMember{offset: p.pos}
+			m.key = p.string()
+
+			p.skipSpace()
+			p.pos++ // the colon
+
+			m.value = p.value()
+			v.members = append(v.members, m)
+		}
+
+		p.pos++
+	case '[':
+		v.typ = "array"
+		p.pos++
+
+		for p.skipSpace(); p.data[p.pos] != ']'; p.skipSpace() {
+			if p.data[p.pos] == ',' {
+				p.pos++
+			}
+
+			v.elements = append(v.elements, p.value())
+		}
+
+		p.pos++
+	case '"':
+		v.typ = "string"
+		v.str = p.string()
+	case 't':
+		v.typ = "boolean"
+		v.boolean = true
+		p.pos += len("true")
+	case 'f':
+		v.typ = "boolean"
+		p.pos += len("false")
+	case 'n':
+		v.typ = "null"
+		p.pos += len("null")
+	default:
+		v.typ = "number"
+		for p.pos < len(p.data) && strings.IndexByte("+-.0123456789eE", p.data[p.pos]) != -1 {
+			p.pos++
+		}
+
+		v.str = string(p.data[v.offset:p.pos])
+	}
+
+	return v
+}
+
+// string consumes a JSON string, and returns its decoded value.
+func (p *parser) string() string {
+	start := p.pos
+
+	for p.pos++; p.data[p.pos] != '"'; p.pos++ {
+		if p.data[p.pos] == '\\' {
+			p.pos++
+		}
+	}
+
+	p.pos++
+
+	var s string
+	json.Unmarshal(p.data[start:p.pos], &s) // the input is known to be valid
+	return s
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.data) {
+		switch p.data[p.pos] {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+// fail returns a *ParseError for a problem at offset. The path of the error is
+// the current path, extended with tokens.
+func (p *parser) fail(offset int, err error, tokens ...string) error {
+	path := make([]string, 0, len(p.path)+len(tokens))
+	path = append(append(path, p.path...), tokens...)
+
+	line, column := position(p.data, offset)
+	return &ParseError{Line: line, Column: column, Path: path, Err: err}
+}
+
+// check returns an error if v is not of JSON type typ.
+func (p *parser) check(v jsonValue, typ string) error {
+	if v.typ != typ {
+		return p.fail(v.offset, ErrWrongJSONType(typ))
+	}
+
+	return nil
+}
+
+// schema converts v into a Schema. root indicates whether v is the root schema,
+// and may therefore have definitions.
+func (p *parser) schema(v jsonValue, root bool) (Schema, error) {
+	var s Schema
+	if err := p.check(v, "object"); err != nil {
+		return s, err
+	}
+
+	seen := map[string]struct{}{}
+	for _, m := range v.members {
+		if _, ok := seen[m.key]; ok {
+			return s, p.fail(m.offset, ErrRepeatedKey(m.key), m.key)
+		}
+
+		seen[m.key] = struct{}{}
+
+		var err error
+		p.path = append(p.path, m.key)
+
+		switch m.key {
+		case "definitions":
+			if !root {
+				err = p.fail(m.offset, ErrNonRootDefinitions)
+			} else {
+				s.Definitions, err = p.schemas(m.value)
+			}
+		case "ref":
+			if err = p.check(m.value, "string"); err == nil {
+				ref := m.value.str
+				s.Ref = &ref
+			}
+		case "type":
+			if err = p.check(m.value, "string"); err == nil {
+				s.Type = Type(m.value.str)
+			}
+		case "enum":
+			s.Enum, err = p.enum(m.value)
+		case "elements":
+			s.Elements, err = p.subSchema(m.value)
+		case "properties":
+			s.RequiredProperties, err = p.schemas(m.value)
+		case "optionalProperties":
+			s.OptionalProperties, err = p.schemas(m.value)
+		case "additionalProperties":
+			if err = p.check(m.value, "boolean"); err == nil {
+				s.AdditionalProperties = m.value.boolean
+			}
+		case "values":
+			s.Values, err = p.subSchema(m.value)
+		case "discriminator":
+			if !p.rfc8927 {
+				s.Discriminator, err = p.discriminator(m.value)
+			} else if err = p.check(m.value, "string"); err == nil {
+				s.Discriminator.Tag = m.value.str
+			}
+		case "mapping":
+			if !p.rfc8927 {
+				err = p.fail(m.offset, ErrUnknownKeyword(m.key))
+			} else {
+				s.Discriminator.Mapping, err = p.schemas(m.value)
+			}
+		case "nullable":
+			if err = p.check(m.value, "boolean"); err == nil {
+				s.Nullable = m.value.boolean
+			}
+		case "metadata":
+			if err = p.check(m.value, "object"); err == nil {
+				var metadata interface{}
+				if metadata, err = p.any(m.value); err == nil {
+					s.Metadata = metadata.(map[string]interface{})
+				}
+			}
+		default:
+			err = p.fail(m.offset, ErrUnknownKeyword(m.key))
+		}
+
+		p.path = p.path[:len(p.path)-1]
+
+		if err != nil {
+			return s, err
+		}
+	}
+
+	// Schema cannot represent a "discriminator" without a "mapping", or the
+	// other way around, and so neither may be absent.
+	_, hasTag := seen["discriminator"]
+	_, hasMapping := seen["mapping"]
+	if p.rfc8927 && hasTag != hasMapping {
+		return s, p.fail(v.offset, ErrInvalidForm)
+	}
+
+	return s, nil
+}
+
+func (p *parser) subSchema(v jsonValue) (*Schema, error) {
+	s, err := p.schema(v, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// schemas converts an object whose values are schemas, such as "properties".
+func (p *parser) schemas(v jsonValue) (map[string]Schema, error) {
+	if err := p.check(v, "object"); err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]Schema, len(v.members))
+	for _, m := range v.members {
+		if _, ok := out[m.key]; ok {
+			return nil, p.fail(m.offset, ErrRepeatedKey(m.key), m.key)
+		}
+
+		p.path = append(p.path, m.key)
+		s, err := p.schema(m.value, false)
+		p.path = p.path[:len(p.path)-1]
+
+		if err != nil {
+			return nil, err
+		}
+
+		out[m.key] = s
+	}
+
+	return out, nil
+}
+
+// any converts v into the same value json.Unmarshal would decode it into, with
+// numbers as float64. Unlike json.Unmarshal, it rejects repeated keys.
+func (p *parser) any(v jsonValue) (interface{}, error) {
+	switch v.typ {
+	case "object":
+		out := make(map[string]interface{}, len(v.members))
+		for _, m := range v.members {
+			if _, ok := out[m.key]; ok {
+				return nil, p.fail(m.offset, ErrRepeatedKey(m.key), m.key)
+			}
+
+			p.path = append(p.path, m.key)
+			val, err := p.any(m.value)
+			p.path = p.path[:len(p.path)-1]
+
+			if err != nil {
+				return nil, err
+			}
+
+			out[m.key] = val
+		}
+
+		return out, nil
+	case "array":
+		out := make([]interface{}, len(v.elements))
+		for i, e := range v.elements {
+			p.path = append(p.path, strconv.Itoa(i))
+			val, err := p.any(e)
+			p.path = p.path[:len(p.path)-1]
+
+			if err != nil {
+				return nil, err
+			}
+
+			out[i] = val
+		}
+
+		return out, nil
+	case "string":
+		return v.str, nil
+	case "boolean":
+		return v.boolean, nil
+	case "number":
+		// ParseSchema has already had json.Unmarshal check that every number
+		// fits in a float64.
+		f, _ := strconv.ParseFloat(v.str, 64)
+		return f, nil
+	default:
+		return nil, nil
+	}
+}
+
+func (p *parser) enum(v jsonValue) ([]string, error) {
+	if err := p.check(v, "array"); err != nil {
+		return nil, err
+	}
+
+	out := make([]string, len(v.elements))
+	for i, e := range v.elements {
+		if e.typ != "string" {
+			return nil, p.fail(e.offset, ErrWrongJSONType("string"), strconv.Itoa(i))
+		}
+
+		out[i] = e.str
+	}
+
+	return out, nil
+}
+
+func (p *parser) discriminator(v jsonValue) (Discriminator, error) {
+	var d Discriminator
+	if err := p.check(v, "object"); err != nil {
+		return d, err
+	}
+
+	seen := map[string]struct{}{}
+	for _, m := range v.members {
+		if _, ok := seen[m.key]; ok {
+			return d, p.fail(m.offset, ErrRepeatedKey(m.key), m.key)
+		}
+
+		seen[m.key] = struct{}{}
+
+		var err error
+		p.path = append(p.path, m.key)
+
+		switch m.key {
+		case "tag":
+			if err = p.check(m.value, "string"); err == nil {
+				d.Tag = m.value.str
+			}
+		case "mapping":
+			d.Mapping, err = p.schemas(m.value)
+		default:
+			err = p.fail(m.offset, ErrUnknownKeyword(m.key))
+		}
+
+		p.path = p.path[:len(p.path)-1]
+
+		if err != nil {
+			return d, err
+		}
+	}
+
+	return d, nil
+}
+
+// position returns the line and column of offset in data, both starting at one.
+func position(data []byte, offset int) (int, int) {
+	before := data[:offset]
+
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := utf8.RuneCount(before[bytes.LastIndexByte(before, '\n')+1:]) + 1
+	return line, column
+}
@@ -0,0 +1,271 @@
+package jsl_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	jsl "github.com/json-schema-language/json-schema-language-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSchema(t *testing.T) {
+	in := `{
+		"definitions": {
+			"user": {
+				"properties": { "name": { "type": "string" } },
+				"optionalProperties": { "tags": { "elements": { "enum": ["a", "b"] } } }
+			}
+		},
+		"discriminator": {
+			"tag": "kind",
+			"mapping": {
+				"x": { "properties": { "user": { "ref": "user" } } },
+				"y": { "properties": { "counts": { "values": { "type": "uint8", "nullable": true } } } }
+			}
+		},
+		"nullable": false,
+		"metadata": { "description": "An event", "version": 2, "tags": [null, true, { "a": -1.5e3 }] }
+	}`
+
+	schema, err := jsl.ParseSchema([]byte(in))
+	assert.NoError(t, err)
+
+	var expected jsl.Schema
+	assert.NoError(t, json.Unmarshal([]byte(in), &expected))
+	assert.Equal(t, expected, schema)
+}
+
+func TestParseRFC8927(t *testing.T) {
+	in := `{
+		"discriminator": "kind",
+		"mapping": {
+			"x": { "properties": { "n": { "type": "int8" } }, "additionalProperties": true }
+		},
+		"nullable": true
+	}`
+
+	schema, err := jsl.ParseRFC8927([]byte(in))
+	assert.NoError(t, err)
+	assert.Equal(t, jsl.Schema{
+		Discriminator: jsl.Discriminator{
+			Tag: "kind",
+			Mapping: map[string]jsl.Schema{
+				"x": jsl.Schema{
+					RequiredProperties:   map[string]jsl.Schema{"n": jsl.Schema{Type: jsl.TypeInt8}},
+					AdditionalProperties: true,
+				},
+			},
+		},
+		Nullable: true,
+	}, schema)
+}
+
+func TestParseSchemaInvalid(t *testing.T) {
+	type testCase struct {
+		name string
+		in   string
+		err  jsl.ParseError
+	}
+
+	testCases := []testCase{
+		{
+			"syntax error",
+			"{\n  \"type\": \"string\",\n}",
+			jsl.ParseError{Line: 3, Column: 1},
+		},
+		{
+			"unknown keyword",
+			"{\n  \"elements\": {\n    \"element\": {}\n  }\n}",
+			jsl.ParseError{
+				Line:   3,
+				Column: 5,
+				Path:   []string{"elements", "element"},
+				Err:    jsl.ErrUnknownKeyword("element"),
+			},
+		},
+		{
+			"unknown keyword in discriminator",
+			`{"discriminator":{"tags":"a"}}`,
+			jsl.ParseError{
+				Line:   1,
+				Column: 19,
+				Path:   []string{"discriminator", "tags"},
+				Err:    jsl.ErrUnknownKeyword("tags"),
+			},
+		},
+		{
+			"non-root definitions",
+			`{"properties":{"a":{"definitions":{}}}}`,
+			jsl.ParseError{
+				Line:   1,
+				Column: 21,
+				Path:   []string{"properties", "a", "definitions"},
+				Err:    jsl.ErrNonRootDefinitions,
+			},
+		},
+		{
+			"wrong type for keyword",
+			"{\"values\": {\"type\": null}}",
+			jsl.ParseError{
+				Line:   1,
+				Column: 21,
+				Path:   []string{"values", "type"},
+				Err:    jsl.ErrWrongJSONType("string"),
+			},
+		},
+		{
+			"wrong type for nullable",
+			`{"nullable":"true"}`,
+			jsl.ParseError{
+				Line:   1,
+				Column: 13,
+				Path:   []string{"nullable"},
+				Err:    jsl.ErrWrongJSONType("boolean"),
+			},
+		},
+		{
+			"wrong type for schema",
+			`{"optionalProperties":{"ä":{},"b":[]}}`,
+			jsl.ParseError{
+				Line:   1,
+				Column: 35,
+				Path:   []string{"optionalProperties", "b"},
+				Err:    jsl.ErrWrongJSONType("object"),
+			},
+		},
+		{
+			"wrong type in enum",
+			`{"enum":["a",1]}`,
+			jsl.ParseError{
+				Line:   1,
+				Column: 14,
+				Path:   []string{"enum", "1"},
+				Err:    jsl.ErrWrongJSONType("string"),
+			},
+		},
+		{
+			"mapping outside of discriminator",
+			`{"mapping":{}}`,
+			jsl.ParseError{
+				Line:   1,
+				Column: 2,
+				Path:   []string{"mapping"},
+				Err:    jsl.ErrUnknownKeyword("mapping"),
+			},
+		},
+		{
+			"wrong type for additionalProperties",
+			`{"properties":{},"additionalProperties":"yes"}`,
+			jsl.ParseError{
+				Line:   1,
+				Column: 41,
+				Path:   []string{"additionalProperties"},
+				Err:    jsl.ErrWrongJSONType("boolean"),
+			},
+		},
+		{
+			"repeated keyword",
+			`{"ref":"a","ref":"b"}`,
+			jsl.ParseError{
+				Line:   1,
+				Column: 12,
+				Path:   []string{"ref"},
+				Err:    jsl.ErrRepeatedKey("ref"),
+			},
+		},
+		{
+			"wrong type for metadata",
+			`{"metadata":[]}`,
+			jsl.ParseError{
+				Line:   1,
+				Column: 13,
+				Path:   []string{"metadata"},
+				Err:    jsl.ErrWrongJSONType("object"),
+			},
+		},
+		{
+			"repeated key in metadata",
+			`{"metadata":{"a":[{"b":1,"b":2}]}}`,
+			jsl.ParseError{
+				Line:   1,
+				Column: 26,
+				Path:   []string{"metadata", "a", "0", "b"},
+				Err:    jsl.ErrRepeatedKey("b"),
+			},
+		},
+		{
+			"repeated property",
+			`{"properties":{"a":{},"a":{}}}`,
+			jsl.ParseError{
+				Line:   1,
+				Column: 23,
+				Path:   []string{"properties", "a"},
+				Err:    jsl.ErrRepeatedKey("a"),
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := jsl.ParseSchema([]byte(tt.in))
+
+			var parseErr *jsl.ParseError
+			assert.True(t, errors.As(err, &parseErr))
+
+			if tt.err.Err == nil {
+				var syntaxErr *json.SyntaxError
+				assert.True(t, errors.As(err, &syntaxErr))
+				tt.err.Err = syntaxErr
+			}
+
+			assert.Equal(t, &tt.err, parseErr)
+		})
+	}
+}
+
+func TestParseRFC8927Invalid(t *testing.T) {
+	type testCase struct {
+		name string
+		in   string
+		err  jsl.ParseError
+	}
+
+	testCases := []testCase{
+		{
+			"JSL discriminator",
+			`{"discriminator":{"tag":"t","mapping":{}}}`,
+			jsl.ParseError{
+				Line:   1,
+				Column: 18,
+				Path:   []string{"discriminator"},
+				Err:    jsl.ErrWrongJSONType("string"),
+			},
+		},
+		{
+			"discriminator without mapping",
+			`{"elements":{"discriminator":"t"}}`,
+			jsl.ParseError{
+				Line:   1,
+				Column: 13,
+				Path:   []string{"elements"},
+				Err:    jsl.ErrInvalidForm,
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := jsl.ParseRFC8927([]byte(tt.in))
+
+			var parseErr *jsl.ParseError
+			assert.True(t, errors.As(err, &parseErr))
+			assert.Equal(t, &tt.err, parseErr)
+		})
+	}
+}
+
+func TestParseErrorMessage(t *testing.T) {
+	_, err := jsl.ParseSchema([]byte("{\n  \"optionalProperty\": {}\n}"))
+	assert.EqualError(t, err, `jsl: unknown keyword: optionalProperty (at "/optionalProperty", line 2, column 3)`)
+}
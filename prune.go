@@ -0,0 +1,105 @@
+package jsl
+
+// Prune returns a copy of an instance, with every property that the schema
+// does not declare removed. It is an alternative to strict instance semantics
+// for data from third parties, where unknown properties are neither errors nor
+// worth keeping.
+//
+// A property is declared if it is in the RequiredProperties or
+// OptionalProperties of a schema of the properties form. The tag of a
+// discriminator is kept in the object it maps to, per the "discriminator tag
+// exemption" in the spec, and so are all the properties of an object whose
+// schema sets AdditionalProperties. Pruning follows refs, elements, values,
+// and the mapping of a discriminator that matches the tag of the instance.
+// Parts of the instance that do not match the schema are copied as they are,
+// and reported once the instance is validated.
+//
+// The instance may be any Go value that Validate accepts. The copy is made up
+// of the types produced by encoding/json when decoding into an interface{},
+// except that Go integers become int64 or uint64. ErrUnsupportedType is
+// returned if the instance contains a value that has no JSON equivalent.
+//
+// Prune returns the same errors as Verify if the schema is not correct.
+func Prune(schema Schema, instance interface{}) (interface{}, error) {
+	if err := schema.Verify(); err != nil {
+		return nil, err
+	}
+
+	return prune(&schema, &schema, instance, nil)
+}
+
+// prune copies instance, pruning it against s. A nil s copies instance in
+// full, as does a schema of the empty form.
+func prune(root, s *Schema, instance interface{}, parentTag *string) (interface{}, error) {
+	instance, err := normalize(instance)
+	if err != nil {
+		return nil, err
+	}
+
+	form := FormEmpty
+	if s != nil {
+		form = s.Form()
+	}
+
+	if form == FormRef {
+		def := root.Definitions[*s.Ref]
+		return prune(root, &def, instance, nil)
+	}
+
+	switch v := instance.(type) {
+	case []interface{}:
+		var elements *Schema
+		if form == FormElements {
+			elements = s.Elements
+		}
+
+		out := make([]interface{}, len(v))
+		for i, elem := range v {
+			if out[i], err = prune(root, elements, elem, nil); err != nil {
+				return nil, err
+			}
+		}
+
+		return out, nil
+	case map[string]interface{}:
+		if form == FormDiscriminator {
+			// Like in validate, the tag may be a Go value that is only a string
+			// once normalized, such as one of a named string type.
+			rawTag, err := normalize(v[s.Discriminator.Tag])
+			if err != nil {
+				return nil, err
+			}
+
+			if tag, ok := rawTag.(string); ok {
+				if mapping, ok := s.Discriminator.Mapping[tag]; ok {
+					return prune(root, &mapping, v, &s.Discriminator.Tag)
+				}
+			}
+		}
+
+		out := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			var sub *Schema
+			switch form {
+			case FormProperties:
+				if required, ok := s.RequiredProperties[k]; ok {
+					sub = &required
+				} else if optional, ok := s.OptionalProperties[k]; ok {
+					sub = &optional
+				} else if !s.AdditionalProperties && (parentTag == nil || k != *parentTag) {
+					continue
+				}
+			case FormValues:
+				sub = s.Values
+			}
+
+			if out[k], err = prune(root, sub, val, nil); err != nil {
+				return nil, err
+			}
+		}
+
+		return out, nil
+	default:
+		return instance, nil
+	}
+}
@@ -0,0 +1,137 @@
+package jsl_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	jsl "github.com/json-schema-language/json-schema-language-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrune(t *testing.T) {
+	type testCase struct {
+		name     string
+		schema   string
+		instance string
+		out      string
+	}
+
+	testCases := []testCase{
+		{
+			"properties",
+			`{"properties":{"id":{"type":"string"}},"optionalProperties":{"tags":{"elements":{"type":"string"}}}}`,
+			`{"id":"a","tags":["b"],"extra":{"x":1}}`,
+			`{"id":"a","tags":["b"]}`,
+		},
+		{
+			"empty form keeps everything",
+			`{"properties":{"payload":{}}}`,
+			`{"payload":{"a":[{"b":1}]},"c":2}`,
+			`{"payload":{"a":[{"b":1}]}}`,
+		},
+		{
+			"additionalProperties",
+			`{"properties":{"a":{"properties":{}}},"additionalProperties":true}`,
+			`{"a":{"x":1},"b":{"y":2}}`,
+			`{"a":{},"b":{"y":2}}`,
+		},
+		{
+			"ref, elements and values",
+			`{
+				"definitions": {"user": {"properties": {"name": {}}}},
+				"properties": {
+					"users": {"elements": {"ref": "user"}},
+					"byId": {"values": {"ref": "user"}}
+				}
+			}`,
+			`{"users":[{"name":"a","x":1},2],"byId":{"1":{"name":"b","y":2}}}`,
+			`{"users":[{"name":"a"},2],"byId":{"1":{"name":"b"}}}`,
+		},
+		{
+			"discriminator tag exemption",
+			`{"elements":{"discriminator":{"tag":"type","mapping":{"push":{"properties":{"ref":{}}}}}}}`,
+			`[{"type":"push","ref":"main","sender":"x"},{"type":"fork","sender":"x"}]`,
+			`[{"type":"push","ref":"main"},{"type":"fork","sender":"x"}]`,
+		},
+		{
+			"mismatched types are kept",
+			`{"properties":{"a":{"type":"string"}}}`,
+			`[{"a":1,"b":2}]`,
+			`[{"a":1,"b":2}]`,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, err := jsl.ParseSchema([]byte(tt.schema))
+			assert.NoError(t, err)
+
+			var instance interface{}
+			assert.NoError(t, json.Unmarshal([]byte(tt.instance), &instance))
+
+			pruned, err := jsl.Prune(schema, instance)
+			assert.NoError(t, err)
+
+			out, err := json.Marshal(pruned)
+			assert.NoError(t, err)
+			assert.JSONEq(t, tt.out, string(out))
+
+			// The instance itself is left as it was.
+			in, err := json.Marshal(instance)
+			assert.NoError(t, err)
+			assert.JSONEq(t, tt.instance, string(in))
+		})
+	}
+}
+
+func TestPruneGoValues(t *testing.T) {
+	type event struct {
+		ID      int               `json:"id"`
+		Headers map[string]string `json:"headers"`
+		Secret  string            `json:"secret"`
+	}
+
+	schema := jsl.Schema{
+		RequiredProperties: map[string]jsl.Schema{
+			"id":      jsl.Schema{Type: jsl.TypeUint32},
+			"headers": jsl.Schema{Values: &jsl.Schema{Type: jsl.TypeString}},
+		},
+	}
+
+	pruned, err := jsl.Prune(schema, event{ID: 1, Headers: map[string]string{"a": "b"}, Secret: "x"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"id":      int64(1),
+		"headers": map[string]interface{}{"a": "b"},
+	}, pruned)
+
+	result, err := (&jsl.Validator{StrictInstanceSemantics: true}).Validate(schema, pruned)
+	assert.NoError(t, err)
+	assert.True(t, result.IsValid())
+
+	type kind string
+	type tagged struct {
+		Kind  kind   `json:"kind"`
+		Name  string `json:"name"`
+		Extra string `json:"extra"`
+	}
+
+	discriminator := jsl.Schema{
+		Discriminator: jsl.Discriminator{
+			Tag: "kind",
+			Mapping: map[string]jsl.Schema{
+				"a": jsl.Schema{RequiredProperties: map[string]jsl.Schema{"name": jsl.Schema{Type: jsl.TypeString}}},
+			},
+		},
+	}
+
+	pruned, err = jsl.Prune(discriminator, tagged{Kind: "a", Name: "x", Extra: "secret"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"kind": "a", "name": "x"}, pruned)
+
+	_, err = jsl.Prune(schema, map[string]interface{}{"id": make(chan int)})
+	assert.Equal(t, jsl.ErrUnsupportedType("chan int"), err)
+
+	_, err = jsl.Prune(jsl.Schema{Ref: strptr("a")}, nil)
+	assert.Equal(t, jsl.ErrNoSuchDefinition("a"), err)
+}
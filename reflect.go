@@ -0,0 +1,252 @@
+package jsl
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	numberType        = reflect.TypeOf(json.Number(""))
+	marshalerType     = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// SchemaFor builds a schema describing the JSON that encoding/json produces for
+// values of type t.
+//
+// Structs become schemas of the properties form, following the same rules for
+// "json" tags and embedded structs as encoding/json. Fields with omitempty are
+// optional properties, and all others are required. Fields with the ",string"
+// option are strings.
+//
+// Named types are put into the definitions of the schema, and referred to with
+// a "ref". This is what allows recursive types to be described. The exceptions
+// are builtin types, json.Number, and types that implement json.Marshaler or
+// encoding.TextMarshaler, which are described where they are used. Definitions
+// are named after their type, or if two types share a name, after their
+// package path as well.
+//
+// Pointers, slices and maps are nullable, since encoding/json encodes nil ones
+// as null.
+//
+// time.Time becomes a timestamp, and sized integers and floats become the type
+// of the same name. int and uint are taken to be 64 bits wide, as is uintptr.
+// json.Number is a number, and []byte a string. Interfaces, json.RawMessage,
+// and other types implementing json.Marshaler, can be any JSON value and so
+// become the empty form. Types implementing encoding.TextMarshaler become
+// strings.
+//
+// SchemaFor returns ErrUnsupportedType if t, or a type within it, cannot be
+// represented as JSON, such as a channel or a function.
+func SchemaFor(t reflect.Type) (Schema, error) {
+	r := reflector{
+		definitions: map[string]Schema{},
+		names:       map[reflect.Type]string{},
+		types:       map[string]reflect.Type{},
+	}
+
+	schema, err := r.schema(t)
+	if err != nil {
+		return Schema{}, err
+	}
+
+	if len(r.definitions) > 0 {
+		schema.Definitions = r.definitions
+	}
+
+	return schema, nil
+}
+
+// SchemaOf is like SchemaFor, but for the type parameter T:
+//
+//	schema, err := jsl.SchemaOf[User]()
+//
+// Unlike reflect.TypeOf, T may also be an interface type.
+func SchemaOf[T any]() (Schema, error) {
+	return SchemaFor(reflect.TypeOf((*T)(nil)).Elem())
+}
+
+// reflector builds a schema from Go types.
+type reflector struct {
+	definitions map[string]Schema
+
+	// names maps the types put into definitions to the name of their
+	// definition, and types does the reverse.
+	names map[reflect.Type]string
+	types map[string]reflect.Type
+}
+
+func (r *reflector) schema(t reflect.Type) (Schema, error) {
+	if t == nil {
+		// The type of a nil interface{}.
+		return Schema{}, nil
+	}
+
+	if t.Name() == "" || t.PkgPath() == "" || t == numberType || marshals(t) {
+		return r.unnamed(t)
+	}
+
+	name, ok := r.names[t]
+	if !ok {
+		name = t.Name()
+		if _, ok := r.types[name]; ok {
+			name = strings.Replace(t.PkgPath(), "/", ".", -1) + "." + t.Name()
+		}
+
+		// Registering the name before building the definition is what keeps
+		// recursive types from recursing forever.
+		r.names[t] = name
+		r.types[name] = t
+
+		def, err := r.unnamed(t)
+		if err != nil {
+			return Schema{}, err
+		}
+
+		r.definitions[name] = def
+	}
+
+	return Schema{Ref: &name}, nil
+}
+
+// unnamed builds the schema for t itself, even if t is named.
+func (r *reflector) unnamed(t reflect.Type) (Schema, error) {
+	switch {
+	case t.Kind() == reflect.Ptr:
+		return nullable(r.schema(t.Elem()))
+	case t == timeType:
+		return Schema{Type: TypeTimestamp}, nil
+	case t == numberType:
+		return Schema{Type: TypeNumber}, nil
+	case implements(t, marshalerType):
+		return Schema{}, nil
+	case implements(t, textMarshalerType):
+		return Schema{Type: TypeString}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return Schema{Type: TypeBoolean}, nil
+	case reflect.Int8:
+		return Schema{Type: TypeInt8}, nil
+	case reflect.Uint8:
+		return Schema{Type: TypeUint8}, nil
+	case reflect.Int16:
+		return Schema{Type: TypeInt16}, nil
+	case reflect.Uint16:
+		return Schema{Type: TypeUint16}, nil
+	case reflect.Int32:
+		return Schema{Type: TypeInt32}, nil
+	case reflect.Uint32:
+		return Schema{Type: TypeUint32}, nil
+	case reflect.Int, reflect.Int64:
+		return Schema{Type: TypeInt64}, nil
+	case reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		return Schema{Type: TypeUint64}, nil
+	case reflect.Float32:
+		return Schema{Type: TypeFloat32}, nil
+	case reflect.Float64:
+		return Schema{Type: TypeFloat64}, nil
+	case reflect.String:
+		return Schema{Type: TypeString}, nil
+	case reflect.Interface:
+		return Schema{}, nil
+	case reflect.Slice, reflect.Array:
+		// Nil slices are encoded as null, but arrays cannot be nil.
+		nullable := t.Kind() == reflect.Slice
+
+		if nullable && t.Elem().Kind() == reflect.Uint8 {
+			return Schema{Type: TypeString, Nullable: true}, nil
+		}
+
+		elements, err := r.schema(t.Elem())
+		if err != nil {
+			return Schema{}, err
+		}
+
+		return Schema{Elements: &elements, Nullable: nullable}, nil
+	case reflect.Map:
+		if !validMapKey(t.Key()) {
+			return Schema{}, ErrUnsupportedType(t.String())
+		}
+
+		values, err := r.schema(t.Elem())
+		if err != nil {
+			return Schema{}, err
+		}
+
+		return Schema{Values: &values, Nullable: true}, nil
+	case reflect.Struct:
+		return r.properties(t)
+	default:
+		return Schema{}, ErrUnsupportedType(t.String())
+	}
+}
+
+func (r *reflector) properties(t reflect.Type) (Schema, error) {
+	var s Schema
+	for _, f := range structFields(t) {
+		sub := Schema{Type: TypeString}
+		if !f.quoted {
+			var err error
+			if sub, err = r.schema(f.typ); err != nil {
+				return Schema{}, err
+			}
+		}
+
+		if f.omitEmpty {
+			if s.OptionalProperties == nil {
+				s.OptionalProperties = map[string]Schema{}
+			}
+
+			s.OptionalProperties[f.name] = sub
+		} else {
+			if s.RequiredProperties == nil {
+				s.RequiredProperties = map[string]Schema{}
+			}
+
+			s.RequiredProperties[f.name] = sub
+		}
+	}
+
+	// A struct without fields is still an object.
+	if s.RequiredProperties == nil && s.OptionalProperties == nil {
+		s.RequiredProperties = map[string]Schema{}
+	}
+
+	return s, nil
+}
+
+// nullable makes a schema nullable, unless it is of the empty form, which
+// accepts null anyway.
+func nullable(s Schema, err error) (Schema, error) {
+	s.Nullable = err == nil && s.Form() != FormEmpty
+	return s, err
+}
+
+// validMapKey returns whether encoding/json can use values of t as object keys.
+func validMapKey(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+
+	return t.Implements(textMarshalerType)
+}
+
+// implements returns whether t or a pointer to t implements iface. encoding/json
+// uses the methods of pointers whenever values are addressable.
+func implements(t, iface reflect.Type) bool {
+	return t.Implements(iface) || reflect.PtrTo(t).Implements(iface)
+}
+
+// marshals returns whether t decides on its own JSON representation.
+func marshals(t reflect.Type) bool {
+	return implements(t, marshalerType) || implements(t, textMarshalerType)
+}
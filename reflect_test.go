@@ -0,0 +1,153 @@
+package jsl_test
+
+import (
+	"encoding/json"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	jsl "github.com/json-schema-language/json-schema-language-go"
+	"github.com/stretchr/testify/assert"
+)
+
+type reflectColor string
+
+type reflectTree struct {
+	Value    int32          `json:"value"`
+	Children []*reflectTree `json:"children,omitempty"`
+}
+
+type reflectBase struct {
+	ID      uint64 `json:"id"`
+	Ignored string `json:"-"`
+}
+
+type reflectUser struct {
+	reflectBase
+	Name       string
+	Color      reflectColor           `json:"color"`
+	Created    time.Time              `json:"created"`
+	Deleted    *time.Time             `json:"deleted,omitempty"`
+	Count      int                    `json:"count,string"`
+	Small      int8                   `json:"small"`
+	Ratio      float32                `json:"ratio"`
+	Data       []byte                 `json:"data"`
+	Raw        json.RawMessage        `json:"raw"`
+	Number     json.Number            `json:"number"`
+	IP         net.IP                 `json:"ip"`
+	Any        interface{}            `json:"any"`
+	Labels     map[string]bool        `json:"labels"`
+	Scores     map[int]float64        `json:"scores"`
+	Tree       reflectTree            `json:"tree"`
+	Anonymous  struct{ A uint16 }     `json:"anonymous"`
+	Extra      map[string]interface{} `json:"extra,omitempty"`
+	unexported bool
+}
+
+func TestSchemaFor(t *testing.T) {
+	schema, err := jsl.SchemaFor(reflect.TypeOf(&reflectUser{}))
+	assert.NoError(t, err)
+
+	var expected jsl.Schema
+	err = json.Unmarshal([]byte(`{
+		"definitions": {
+			"reflectColor": { "type": "string" },
+			"reflectTree": {
+				"properties": { "value": { "type": "int32" } },
+				"optionalProperties": {
+					"children": { "elements": { "ref": "reflectTree", "nullable": true }, "nullable": true }
+				}
+			},
+			"reflectUser": {
+				"properties": {
+					"id": { "type": "uint64" },
+					"Name": { "type": "string" },
+					"color": { "ref": "reflectColor" },
+					"created": { "type": "timestamp" },
+					"count": { "type": "string" },
+					"small": { "type": "int8" },
+					"ratio": { "type": "float32" },
+					"data": { "type": "string", "nullable": true },
+					"raw": {},
+					"number": { "type": "number" },
+					"ip": { "type": "string" },
+					"any": {},
+					"labels": { "values": { "type": "boolean" }, "nullable": true },
+					"scores": { "values": { "type": "float64" }, "nullable": true },
+					"tree": { "ref": "reflectTree" },
+					"anonymous": { "properties": { "A": { "type": "uint16" } } }
+				},
+				"optionalProperties": {
+					"deleted": { "type": "timestamp", "nullable": true },
+					"extra": { "values": {}, "nullable": true }
+				}
+			}
+		},
+		"ref": "reflectUser",
+		"nullable": true
+	}`), &expected)
+	assert.NoError(t, err)
+
+	assert.Equal(t, expected, schema)
+	assert.NoError(t, schema.Verify())
+
+	// Values produced by encoding/json from the type satisfy the schema.
+	validator := jsl.Validator{StrictInstanceSemantics: true}
+	result, err := validator.Validate(schema, reflectUser{
+		Data:   []byte{1, 2},
+		Labels: map[string]bool{"a": true},
+		Scores: map[int]float64{1: 0.5},
+		Tree:   reflectTree{Children: []*reflectTree{{Value: 1}}},
+	})
+	assert.NoError(t, err)
+	assert.True(t, result.IsValid(), "%v", result.Errors)
+
+	// So do nil pointers, slices and maps, which are encoded as null.
+	result, err = validator.Validate(schema, &reflectUser{})
+	assert.NoError(t, err)
+	assert.True(t, result.IsValid(), "%v", result.Errors)
+
+	result, err = validator.Validate(schema, (*reflectUser)(nil))
+	assert.NoError(t, err)
+	assert.True(t, result.IsValid(), "%v", result.Errors)
+}
+
+func TestSchemaOf(t *testing.T) {
+	type empty struct{}
+
+	type testCase struct {
+		schemaOf func() (jsl.Schema, error)
+		out      jsl.Schema
+		err      error
+	}
+
+	testCases := []testCase{
+		{jsl.SchemaOf[bool], jsl.Schema{Type: jsl.TypeBoolean}, nil},
+		{jsl.SchemaOf[uint], jsl.Schema{Type: jsl.TypeUint64}, nil},
+		{jsl.SchemaOf[[2]string], jsl.Schema{Elements: &jsl.Schema{Type: jsl.TypeString}}, nil},
+		{jsl.SchemaOf[[]string], jsl.Schema{Elements: &jsl.Schema{Type: jsl.TypeString}, Nullable: true}, nil},
+		{jsl.SchemaOf[*interface{}], jsl.Schema{}, nil},
+		{jsl.SchemaOf[interface{}], jsl.Schema{}, nil},
+		{jsl.SchemaOf[json.Marshaler], jsl.Schema{}, nil},
+		{
+			jsl.SchemaOf[empty],
+			jsl.Schema{
+				Definitions: map[string]jsl.Schema{
+					"empty": jsl.Schema{RequiredProperties: map[string]jsl.Schema{}},
+				},
+				Ref: strptr("empty"),
+			},
+			nil,
+		},
+		{jsl.SchemaOf[chan int], jsl.Schema{}, jsl.ErrUnsupportedType("chan int")},
+		{jsl.SchemaOf[map[bool]string], jsl.Schema{}, jsl.ErrUnsupportedType("map[bool]string")},
+		{jsl.SchemaOf[struct{ F func() }], jsl.Schema{}, jsl.ErrUnsupportedType("func()")},
+	}
+
+	for _, tt := range testCases {
+		schema, err := tt.schemaOf()
+		assert.Equal(t, tt.err, err)
+		assert.Equal(t, tt.out, schema)
+	}
+}
@@ -1,10 +1,20 @@
 package jsl
 
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+)
+
 // Schema represents a JSON Schema Language schema.
 //
 // This type is designed for conversion to/from JSON. However, not all instances
 // of this type are "correct" schemas as defined by the JSL spec. To verify the
 // correctness of a schema, use Verify.
+//
+// Schemas written as RFC 8927 (JSON Type Definition) documents, which spell
+// the discriminator form differently, are converted to and from this type with
+// ParseRFC8927 and MarshalRFC8927.
 type Schema struct {
 	Definitions        map[string]Schema `json:"definitions"`
 	Ref                *string           `json:"ref"`
@@ -13,8 +23,21 @@ type Schema struct {
 	Elements           *Schema           `json:"elements"`
 	RequiredProperties map[string]Schema `json:"properties"`
 	OptionalProperties map[string]Schema `json:"optionalProperties"`
-	Values             *Schema           `json:"values"`
-	Discriminator      Discriminator     `json:"discriminator"`
+
+	// AdditionalProperties allows properties other than those in
+	// RequiredProperties and OptionalProperties, even under strict instance
+	// semantics or RFC 8927. It may only be set on a schema of the properties
+	// form.
+	AdditionalProperties bool `json:"additionalProperties"`
+
+	Values        *Schema       `json:"values"`
+	Discriminator Discriminator `json:"discriminator"`
+	Nullable      bool          `json:"nullable"`
+
+	// Metadata holds annotations for people and tools, such as descriptions or
+	// hints for code generators. It may be present on a schema of any form, and
+	// does not affect validation.
+	Metadata map[string]interface{} `json:"metadata"`
 }
 
 // Type represents the correct values for Type in Schema.
@@ -103,6 +126,10 @@ const (
 
 // Form determines which form a schema takes on, assuming it is correct.
 //
+// Nullable does not affect the form. A schema of any form that is nullable
+// also accepts null, and so a nullable schema of the empty form is no
+// different from one that is not.
+//
 // If the Schema is not correct, then this function's return value is not
 // meaningful.
 func (s *Schema) Form() Form {
@@ -125,141 +152,515 @@ func (s *Schema) Form() Form {
 	}
 }
 
+// MarshalJSON encodes a schema in its canonical form. Only the definitions,
+// the keywords of the schema's form, "additionalProperties" and "nullable" if
+// they are true, and "metadata" are emitted, in the order in which they are
+// declared in Schema. Objects within the schema have their keys sorted.
+//
+// The keywords of other forms are not emitted, so a schema that is not correct
+// may not survive being encoded and decoded again. Correct schemas always do.
+func (s Schema) MarshalJSON() ([]byte, error) {
+	// canonicalSchema uses pointers for every keyword so that omitempty drops
+	// only the keywords that are absent, and not empty values of them.
+	type canonicalSchema struct {
+		Definitions          *map[string]Schema      `json:"definitions,omitempty"`
+		Ref                  *string                 `json:"ref,omitempty"`
+		Type                 Type                    `json:"type,omitempty"`
+		Enum                 *[]string               `json:"enum,omitempty"`
+		Elements             *Schema                 `json:"elements,omitempty"`
+		RequiredProperties   *map[string]Schema      `json:"properties,omitempty"`
+		OptionalProperties   *map[string]Schema      `json:"optionalProperties,omitempty"`
+		AdditionalProperties bool                    `json:"additionalProperties,omitempty"`
+		Values               *Schema                 `json:"values,omitempty"`
+		Discriminator        *Discriminator          `json:"discriminator,omitempty"`
+		Nullable             bool                    `json:"nullable,omitempty"`
+		Metadata             *map[string]interface{} `json:"metadata,omitempty"`
+	}
+
+	out := canonicalSchema{Nullable: s.Nullable}
+	if s.Metadata != nil {
+		out.Metadata = &s.Metadata
+	}
+
+	if s.Definitions != nil {
+		out.Definitions = &s.Definitions
+	}
+
+	switch s.Form() {
+	case FormRef:
+		out.Ref = s.Ref
+	case FormType:
+		out.Type = s.Type
+	case FormEnum:
+		out.Enum = &s.Enum
+	case FormElements:
+		out.Elements = s.Elements
+	case FormProperties:
+		if s.RequiredProperties != nil {
+			out.RequiredProperties = &s.RequiredProperties
+		}
+
+		if s.OptionalProperties != nil {
+			out.OptionalProperties = &s.OptionalProperties
+		}
+
+		out.AdditionalProperties = s.AdditionalProperties
+	case FormValues:
+		out.Values = s.Values
+	case FormDiscriminator:
+		out.Discriminator = &s.Discriminator
+	}
+
+	return json.Marshal(out)
+}
+
+// MarshalRFC8927 encodes a schema as a RFC 8927 (JSON Type Definition)
+// document, in the same canonical form as MarshalJSON. It is the counterpart of
+// ParseRFC8927.
+//
+// RFC 8927 has no TypeNumber, which is encoded as TypeFloat64 since both accept
+// any number. TypeInt64 and TypeUint64 have no equivalent, and a *SchemaError
+// wrapping ErrInvalidType is returned if the schema uses them. Definitions
+// anywhere other than the root are not encoded, since refs never point to them.
+//
+// Note that under RFC 8927, unknown properties are banned unless
+// AdditionalProperties is set. A schema meant to be used without strict
+// instance semantics should set AdditionalProperties before being encoded.
+func MarshalRFC8927(s Schema) ([]byte, error) {
+	out, err := toRFC8927(&s, nil, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(out)
+}
+
+// rfc8927Schema is the canonical encoding of a schema in the syntax of RFC
+// 8927. See canonicalSchema in MarshalJSON.
+type rfc8927Schema struct {
+	Definitions          *map[string]rfc8927Schema `json:"definitions,omitempty"`
+	Ref                  *string                   `json:"ref,omitempty"`
+	Type                 Type                      `json:"type,omitempty"`
+	Enum                 *[]string                 `json:"enum,omitempty"`
+	Elements             *rfc8927Schema            `json:"elements,omitempty"`
+	RequiredProperties   *map[string]rfc8927Schema `json:"properties,omitempty"`
+	OptionalProperties   *map[string]rfc8927Schema `json:"optionalProperties,omitempty"`
+	AdditionalProperties bool                      `json:"additionalProperties,omitempty"`
+	Values               *rfc8927Schema            `json:"values,omitempty"`
+	Discriminator        *string                   `json:"discriminator,omitempty"`
+	Mapping              *map[string]rfc8927Schema `json:"mapping,omitempty"`
+	Nullable             bool                      `json:"nullable,omitempty"`
+	Metadata             *map[string]interface{}   `json:"metadata,omitempty"`
+}
+
+func toRFC8927(s *Schema, path []string, root bool) (rfc8927Schema, error) {
+	out := rfc8927Schema{Nullable: s.Nullable}
+	if s.Metadata != nil {
+		out.Metadata = &s.Metadata
+	}
+
+	var err error
+	if root && s.Definitions != nil {
+		var defs map[string]rfc8927Schema
+		if defs, err = toRFC8927s(s.Definitions, extend(path, "definitions")); err != nil {
+			return out, err
+		}
+
+		out.Definitions = &defs
+	}
+
+	switch s.Form() {
+	case FormRef:
+		out.Ref = s.Ref
+	case FormType:
+		switch s.Type {
+		case TypeNumber:
+			out.Type = TypeFloat64
+		case TypeInt64, TypeUint64:
+			return out, &SchemaError{Path: extend(path, "type"), Err: ErrInvalidType(s.Type)}
+		default:
+			out.Type = s.Type
+		}
+	case FormEnum:
+		out.Enum = &s.Enum
+	case FormElements:
+		var elements rfc8927Schema
+		if elements, err = toRFC8927(s.Elements, extend(path, "elements"), false); err != nil {
+			return out, err
+		}
+
+		out.Elements = &elements
+	case FormProperties:
+		if s.RequiredProperties != nil {
+			var required map[string]rfc8927Schema
+			if required, err = toRFC8927s(s.RequiredProperties, extend(path, "properties")); err != nil {
+				return out, err
+			}
+
+			out.RequiredProperties = &required
+		}
+
+		if s.OptionalProperties != nil {
+			var optional map[string]rfc8927Schema
+			if optional, err = toRFC8927s(s.OptionalProperties, extend(path, "optionalProperties")); err != nil {
+				return out, err
+			}
+
+			out.OptionalProperties = &optional
+		}
+
+		out.AdditionalProperties = s.AdditionalProperties
+	case FormValues:
+		var values rfc8927Schema
+		if values, err = toRFC8927(s.Values, extend(path, "values"), false); err != nil {
+			return out, err
+		}
+
+		out.Values = &values
+	case FormDiscriminator:
+		var mapping map[string]rfc8927Schema
+		if mapping, err = toRFC8927s(s.Discriminator.Mapping, extend(path, "mapping")); err != nil {
+			return out, err
+		}
+
+		out.Discriminator = &s.Discriminator.Tag
+		out.Mapping = &mapping
+	}
+
+	return out, nil
+}
+
+// toRFC8927s converts the schemas of a keyword such as "properties". path is
+// the path to the keyword.
+func toRFC8927s(schemas map[string]Schema, path []string) (map[string]rfc8927Schema, error) {
+	out := make(map[string]rfc8927Schema, len(schemas))
+	for _, k := range sortedSchemaKeys(schemas) {
+		sub := schemas[k]
+
+		var err error
+		if out[k], err = toRFC8927(&sub, extend(path, k), false); err != nil {
+			return nil, err
+		}
+	}
+
+	return out, nil
+}
+
 // Verify returns nil if a schema is correct, or an error if it is not. The
 // error contains details on the first encountered problem with the correctness
 // of the schema.
+//
+// Use VerifyAll to get every problem with the schema, and where in the schema
+// each problem is.
 func (s *Schema) Verify() error {
-	return s.verify(s)
+	if errs := s.VerifyAll(); len(errs) > 0 {
+		return errs[0].Err
+	}
+
+	return nil
 }
 
-func (s *Schema) verify(root *Schema) error {
-	isEmpty := true
+// VerifyAll returns every problem with the correctness of a schema, or nil if
+// the schema is correct. Problems are returned in a deterministic order, and
+// Verify returns the first of them.
+//
+// Each SchemaError wraps one of the errors that Verify may return, and carries
+// the path to the part of the schema where the problem was found.
+func (s *Schema) VerifyAll() []*SchemaError {
+	return s.verifyAll(false)
+}
 
-	if s.Ref != nil {
-		if root.Definitions == nil {
-			return ErrNoSuchDefinition(*s.Ref)
+// VerifyRFC8927 is like Verify, but checks that a schema is correct according
+// to RFC 8927 (JSON Type Definition) rather than the JSL spec.
+//
+// RFC 8927 is stricter: it has no TypeNumber, TypeInt64 or TypeUint64, it
+// does not allow an enum without values, and it only allows definitions on the
+// root schema. Like Verify, VerifyRFC8927 also rejects definitions whose refs
+// form a cycle.
+func (s *Schema) VerifyRFC8927() error {
+	if errs := s.VerifyAllRFC8927(); len(errs) > 0 {
+		return errs[0].Err
+	}
+
+	return nil
+}
+
+// VerifyAllRFC8927 is like VerifyAll, but checks that a schema is correct
+// according to RFC 8927. See VerifyRFC8927.
+func (s *Schema) VerifyAllRFC8927() []*SchemaError {
+	return s.verifyAll(true)
+}
+
+func (s *Schema) verifyAll(rfc8927 bool) []*SchemaError {
+	v := verifier{root: s, rfc8927: rfc8927}
+
+	for _, name := range sortedSchemaKeys(s.Definitions) {
+		def := s.Definitions[name]
+
+		v.push("definitions", name)
+		v.verify(&def)
+
+		if s.isCyclicRef(name) {
+			v.report(ErrCyclicRef(name), "ref")
+		}
+
+		v.pop(2)
+	}
+
+	v.verify(s)
+	return v.errs
+}
+
+// isCyclicRef returns whether following the ref of the given definition, and
+// the refs of the definitions it leads to, ever leads back to it. Validating
+// against such a definition would never make progress through the instance.
+func (s *Schema) isCyclicRef(name string) bool {
+	def := s.Definitions[name]
+
+	// A chain of refs that is longer than the number of definitions must have
+	// entered some cycle, but not necessarily one that includes name.
+	for i := 0; i < len(s.Definitions) && def.Form() == FormRef; i++ {
+		if *def.Ref == name {
+			return true
 		}
 
-		if _, ok := root.Definitions[*s.Ref]; !ok {
-			return ErrNoSuchDefinition(*s.Ref)
+		def = s.Definitions[*def.Ref]
+	}
+
+	return false
+}
+
+// UnreachableDefinitions returns the sorted names of the definitions that are
+// not used by the root schema, neither directly nor by way of other
+// definitions. Such definitions are allowed, but are often a mistake.
+func (s *Schema) UnreachableDefinitions() []string {
+	reachable := map[string]struct{}{}
+
+	var visit func(sub *Schema)
+	visit = func(sub *Schema) {
+		sub.walkRefs(func(name string) {
+			if _, ok := reachable[name]; ok {
+				return
+			}
+
+			reachable[name] = struct{}{}
+			if def, ok := s.Definitions[name]; ok {
+				visit(&def)
+			}
+		})
+	}
+
+	visit(s)
+
+	var out []string
+	for _, name := range sortedSchemaKeys(s.Definitions) {
+		if _, ok := reachable[name]; !ok {
+			out = append(out, name)
+		}
+	}
+
+	return out
+}
+
+// walkRefs calls f with every ref in the schema and its sub-schemas, not
+// including its definitions.
+func (s *Schema) walkRefs(f func(string)) {
+	if s.Ref != nil {
+		f(*s.Ref)
+	}
+
+	if s.Elements != nil {
+		s.Elements.walkRefs(f)
+	}
+
+	for _, sub := range s.RequiredProperties {
+		sub.walkRefs(f)
+	}
+
+	for _, sub := range s.OptionalProperties {
+		sub.walkRefs(f)
+	}
+
+	if s.Values != nil {
+		s.Values.walkRefs(f)
+	}
+
+	for _, sub := range s.Discriminator.Mapping {
+		sub.walkRefs(f)
+	}
+}
+
+// verifier accumulates the problems found in a schema. rfc8927 is whether
+// to check the schema against RFC 8927 rather than the JSL spec.
+type verifier struct {
+	root    *Schema
+	rfc8927 bool
+	path    []string
+	errs    []*SchemaError
+}
+
+func (v *verifier) push(tokens ...string) {
+	v.path = append(v.path, tokens...)
+}
+
+func (v *verifier) pop(n int) {
+	v.path = v.path[:len(v.path)-n]
+}
+
+// report records a problem at the current path, extended with tokens.
+func (v *verifier) report(err error, tokens ...string) {
+	path := make([]string, 0, len(v.path)+len(tokens))
+	path = append(append(path, v.path...), tokens...)
+
+	v.errs = append(v.errs, &SchemaError{Path: path, Err: err})
+}
+
+func (v *verifier) verify(s *Schema) {
+	isEmpty := true
+	reportedForm := false
+
+	// Each keyword of a form is only allowed if no other form's keywords were
+	// seen before it. The form is reported as invalid only once, but the
+	// keywords are checked regardless, so that all problems are found.
+	checkForm := func() {
+		if !isEmpty && !reportedForm {
+			v.report(ErrInvalidForm)
+			reportedForm = true
 		}
 
 		isEmpty = false
 	}
 
-	if s.Type != "" {
-		if !isEmpty {
-			return ErrInvalidForm
+	if v.rfc8927 && s != v.root && s.Definitions != nil {
+		v.report(ErrNonRootDefinitions, "definitions")
+	}
+
+	if s.Ref != nil {
+		if _, ok := v.root.Definitions[*s.Ref]; !ok {
+			v.report(ErrNoSuchDefinition(*s.Ref), "ref")
 		}
 
+		checkForm()
+	}
+
+	if s.Type != "" {
+		checkForm()
+
 		switch s.Type {
-		case "boolean", "number", "float32", "float64", "int8", "uint8", "int16",
-			"uint16", "int32", "uint32", "int64", "uint64", "string", "timestamp":
+		case "boolean", "float32", "float64", "int8", "uint8", "int16", "uint16",
+			"int32", "uint32", "string", "timestamp":
+		case "number", "int64", "uint64":
+			if v.rfc8927 {
+				v.report(ErrInvalidType(s.Type), "type")
+			}
 		default:
-			return ErrInvalidType(s.Type)
+			v.report(ErrInvalidType(s.Type), "type")
 		}
-
-		isEmpty = false
 	}
 
 	if s.Enum != nil {
-		if !isEmpty {
-			return ErrInvalidForm
+		checkForm()
+
+		if v.rfc8927 && len(s.Enum) == 0 {
+			v.report(ErrEmptyEnum, "enum")
 		}
 
 		vals := map[string]struct{}{}
-		for _, val := range s.Enum {
+		for i, val := range s.Enum {
 			if _, ok := vals[val]; ok {
-				return ErrRepeatedEnumValue(val)
+				v.report(ErrRepeatedEnumValue(val), "enum", strconv.Itoa(i))
 			}
 
 			vals[val] = struct{}{}
 		}
-
-		isEmpty = false
 	}
 
 	if s.Elements != nil {
-		if !isEmpty {
-			return ErrInvalidForm
-		}
+		checkForm()
 
-		if err := s.Elements.verify(root); err != nil {
-			return err
-		}
-
-		isEmpty = false
+		v.push("elements")
+		v.verify(s.Elements)
+		v.pop(1)
 	}
 
 	if s.RequiredProperties != nil || s.OptionalProperties != nil {
-		if !isEmpty {
-			return ErrInvalidForm
-		}
+		checkForm()
 
-		if s.RequiredProperties != nil && s.OptionalProperties != nil {
-			for k := range s.RequiredProperties {
-				if _, ok := s.OptionalProperties[k]; ok {
-					return ErrRepeatedProperty(k)
-				}
+		for _, k := range sortedSchemaKeys(s.OptionalProperties) {
+			if _, ok := s.RequiredProperties[k]; ok {
+				v.report(ErrRepeatedProperty(k), "optionalProperties", k)
 			}
 		}
 
-		for _, s := range s.RequiredProperties {
-			if err := s.verify(root); err != nil {
-				return err
-			}
-		}
+		for _, k := range sortedSchemaKeys(s.RequiredProperties) {
+			sub := s.RequiredProperties[k]
 
-		for _, s := range s.OptionalProperties {
-			if err := s.verify(root); err != nil {
-				return err
-			}
+			v.push("properties", k)
+			v.verify(&sub)
+			v.pop(2)
 		}
 
-		isEmpty = false
-	}
+		for _, k := range sortedSchemaKeys(s.OptionalProperties) {
+			sub := s.OptionalProperties[k]
 
-	if s.Values != nil {
-		if !isEmpty {
-			return ErrInvalidForm
+			v.push("optionalProperties", k)
+			v.verify(&sub)
+			v.pop(2)
 		}
+	} else if s.AdditionalProperties {
+		v.report(ErrInvalidForm, "additionalProperties")
+	}
 
-		if err := s.Values.verify(root); err != nil {
-			return err
-		}
+	if s.Values != nil {
+		checkForm()
 
-		isEmpty = false
+		v.push("values")
+		v.verify(s.Values)
+		v.pop(1)
 	}
 
 	if s.Discriminator.Mapping != nil {
-		if !isEmpty {
-			return ErrInvalidForm
-		}
+		checkForm()
+
+		depth := len(v.path)
+		for _, k := range sortedSchemaKeys(s.Discriminator.Mapping) {
+			m := s.Discriminator.Mapping[k]
 
-		for _, m := range s.Discriminator.Mapping {
-			if err := m.verify(root); err != nil {
-				return err
+			if v.rfc8927 {
+				v.push("mapping", k)
+			} else {
+				v.push("discriminator", "mapping", k)
 			}
 
+			v.verify(&m)
+
 			if m.Form() != FormProperties {
-				return ErrNonPropertiesMapping
+				v.report(ErrNonPropertiesMapping)
+			}
+
+			if m.Nullable {
+				v.report(ErrNullableMapping, "nullable")
 			}
 
-			for k := range m.RequiredProperties {
-				if k == s.Discriminator.Tag {
-					return ErrRepeatedTagInProperties(s.Discriminator.Tag)
-				}
+			if _, ok := m.RequiredProperties[s.Discriminator.Tag]; ok {
+				v.report(ErrRepeatedTagInProperties(s.Discriminator.Tag), "properties", s.Discriminator.Tag)
 			}
 
-			for k := range m.OptionalProperties {
-				if k == s.Discriminator.Tag {
-					return ErrRepeatedTagInProperties(s.Discriminator.Tag)
-				}
+			if _, ok := m.OptionalProperties[s.Discriminator.Tag]; ok {
+				v.report(ErrRepeatedTagInProperties(s.Discriminator.Tag), "optionalProperties", s.Discriminator.Tag)
 			}
+
+			v.pop(len(v.path) - depth)
 		}
+	}
+}
 
-		isEmpty = false
+func sortedSchemaKeys(schemas map[string]Schema) []string {
+	keys := make([]string, 0, len(schemas))
+	for k := range schemas {
+		keys = append(keys, k)
 	}
 
-	return nil
+	sort.Strings(keys)
+	return keys
 }
@@ -2,6 +2,7 @@ package jsl_test
 
 import (
 	"encoding/json"
+	"errors"
 	"testing"
 
 	jsl "github.com/json-schema-language/json-schema-language-go"
@@ -243,6 +244,84 @@ func TestVerifyAndForm(t *testing.T) {
 			nil,
 			jsl.FormDiscriminator,
 		},
+		{
+			`{"nullable":true}`,
+			jsl.Schema{Nullable: true},
+			nil,
+			jsl.FormEmpty,
+		},
+		{
+			`{"definitions":{"":{}},"ref":"","nullable":true}`,
+			jsl.Schema{
+				Definitions: map[string]jsl.Schema{"": jsl.Schema{}},
+				Ref:         strptr(""),
+				Nullable:    true,
+			},
+			nil,
+			jsl.FormRef,
+		},
+		{
+			`{"discriminator":{"tag":"a","mapping":{"":{"properties":{}}}},"nullable":true}`,
+			jsl.Schema{
+				Discriminator: jsl.Discriminator{
+					Tag: "a",
+					Mapping: map[string]jsl.Schema{
+						"": jsl.Schema{RequiredProperties: map[string]jsl.Schema{}},
+					},
+				},
+				Nullable: true,
+			},
+			nil,
+			jsl.FormDiscriminator,
+		},
+		{
+			`{"discriminator":{"tag":"a","mapping":{"":{"properties":{},"nullable":true}}}}`,
+			jsl.Schema{
+				Discriminator: jsl.Discriminator{
+					Tag: "a",
+					Mapping: map[string]jsl.Schema{
+						"": jsl.Schema{RequiredProperties: map[string]jsl.Schema{}, Nullable: true},
+					},
+				},
+			},
+			jsl.ErrNullableMapping,
+			jsl.FormDiscriminator,
+		},
+		{
+			`{"metadata":{"description":"a","tags":["x"]},"type":"string"}`,
+			jsl.Schema{
+				Type: jsl.TypeString,
+				Metadata: map[string]interface{}{
+					"description": "a",
+					"tags":        []interface{}{"x"},
+				},
+			},
+			nil,
+			jsl.FormType,
+		},
+		{
+			`{"discriminator":{"tag":"a","mapping":{"":{"properties":{},"metadata":{"n":1}}}},"metadata":{}}`,
+			jsl.Schema{
+				Discriminator: jsl.Discriminator{
+					Tag: "a",
+					Mapping: map[string]jsl.Schema{
+						"": jsl.Schema{
+							RequiredProperties: map[string]jsl.Schema{},
+							Metadata:           map[string]interface{}{"n": 1.0},
+						},
+					},
+				},
+				Metadata: map[string]interface{}{},
+			},
+			nil,
+			jsl.FormDiscriminator,
+		},
+		{
+			`{"values":{},"additionalProperties":true}`,
+			jsl.Schema{Values: &jsl.Schema{}, AdditionalProperties: true},
+			jsl.ErrInvalidForm,
+			jsl.FormValues,
+		},
 	}
 
 	for _, tt := range testCases {
@@ -253,6 +332,235 @@ func TestVerifyAndForm(t *testing.T) {
 			assert.Equal(t, tt.out, out)
 			assert.Equal(t, tt.err, out.Verify())
 			assert.Equal(t, tt.form, out.Form())
+
+			if tt.err == nil {
+				data, err := json.Marshal(out)
+				assert.NoError(t, err)
+
+				var roundTrip jsl.Schema
+				assert.NoError(t, json.Unmarshal(data, &roundTrip))
+				assert.Equal(t, out, roundTrip)
+			}
+		})
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	type testCase struct {
+		in  string
+		out string
+	}
+
+	testCases := []testCase{
+		{`{}`, `{}`},
+		{`{"definitions":{}}`, `{"definitions":{}}`},
+		{`{"ref":"a","definitions":{"a":{}}}`, `{"definitions":{"a":{}},"ref":"a"}`},
+		{`{"type":"string"}`, `{"type":"string"}`},
+		{`{"enum":[]}`, `{"enum":[]}`},
+		{`{"enum":["b","a"]}`, `{"enum":["b","a"]}`},
+		{`{"elements":{"type":"uint8"}}`, `{"elements":{"type":"uint8"}}`},
+		{`{"properties":{}}`, `{"properties":{}}`},
+		{
+			`{"optionalProperties":{"b":{},"a":{}},"properties":{"c":{}}}`,
+			`{"properties":{"c":{}},"optionalProperties":{"a":{},"b":{}}}`,
+		},
+		{`{"values":{"values":{}}}`, `{"values":{"values":{}}}`},
+		{
+			`{"discriminator":{"mapping":{"b":{"properties":{}},"a":{"properties":{}}},"tag":"t"}}`,
+			`{"discriminator":{"tag":"t","mapping":{"a":{"properties":{}},"b":{"properties":{}}}}}`,
+		},
+		{`{"nullable":true,"type":"string"}`, `{"type":"string","nullable":true}`},
+		{`{"nullable":false,"type":"string"}`, `{"type":"string"}`},
+		{`{"metadata":{"b":null,"a":[1]},"values":{}}`, `{"values":{},"metadata":{"a":[1],"b":null}}`},
+		{`{"metadata":{}}`, `{"metadata":{}}`},
+		{`{"additionalProperties":true,"properties":{}}`, `{"properties":{},"additionalProperties":true}`},
+		{`{"additionalProperties":true,"type":"string"}`, `{"type":"string"}`},
+		// Keywords of other forms than the schema's own are dropped.
+		{`{"type":"string","elements":{},"discriminator":{"tag":"t"}}`, `{"type":"string"}`},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.in, func(t *testing.T) {
+			var schema jsl.Schema
+			assert.NoError(t, json.Unmarshal([]byte(tt.in), &schema))
+
+			out, err := json.Marshal(schema)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.out, string(out))
+
+			out, err = json.Marshal(&schema)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.out, string(out))
 		})
 	}
 }
+
+func TestVerifyAll(t *testing.T) {
+	var schema jsl.Schema
+	err := json.Unmarshal([]byte(`{
+		"definitions": {
+			"user": {
+				"properties": {
+					"name": { "type": "string" },
+					"address": { "type": "string", "enum": ["a", "b", "a"] }
+				},
+				"optionalProperties": {
+					"name": { "ref": "missing" }
+				}
+			},
+			"event": {
+				"discriminator": {
+					"tag": "kind",
+					"mapping": {
+						"a": { "type": "string" },
+						"b": { "properties": { "kind": {} } }
+					}
+				}
+			}
+		},
+		"elements": { "type": "nonsense" }
+	}`), &schema)
+	assert.NoError(t, err)
+
+	errs := schema.VerifyAll()
+
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+
+	assert.Equal(t, []string{
+		`jsl: value of discriminator mapping is not of properties form (at "/definitions/event/discriminator/mapping/a")`,
+		`jsl: discriminator tag repeated in properties or optionalProperties: kind (at "/definitions/event/discriminator/mapping/b/properties/kind")`,
+		`jsl: repeated property in properties and optionalProperties: name (at "/definitions/user/optionalProperties/name")`,
+		`jsl: ambiguous or invalid schema form (at "/definitions/user/properties/address")`,
+		`jsl: repeated enum value: a (at "/definitions/user/properties/address/enum/2")`,
+		`jsl: no such definition: missing (at "/definitions/user/optionalProperties/name/ref")`,
+		`jsl: no such type: nonsense (at "/elements/type")`,
+	}, messages)
+
+	assert.Equal(t, errs[0].Err, schema.Verify())
+	assert.True(t, errors.Is(errs[0], jsl.ErrNonPropertiesMapping))
+
+	var refErr jsl.ErrNoSuchDefinition
+	assert.True(t, errors.As(errs[5], &refErr))
+	assert.Equal(t, jsl.ErrNoSuchDefinition("missing"), refErr)
+
+	assert.Nil(t, (&jsl.Schema{}).VerifyAll())
+}
+
+func TestVerifyAllRFC8927(t *testing.T) {
+	schema, err := jsl.ParseRFC8927([]byte(`{
+		"definitions": {
+			"id": { "type": "uint64" },
+			"tags": { "enum": [] }
+		},
+		"properties": {
+			"kind": { "discriminator": "t", "mapping": { "a": { "type": "number" } } }
+		}
+	}`))
+	assert.NoError(t, err)
+
+	schema.RequiredProperties["nested"] = jsl.Schema{Definitions: map[string]jsl.Schema{}}
+
+	errs := schema.VerifyAllRFC8927()
+
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+
+	assert.Equal(t, []string{
+		`jsl: no such type: uint64 (at "/definitions/id/type")`,
+		`jsl: enum has no values (at "/definitions/tags/enum")`,
+		`jsl: no such type: number (at "/properties/kind/mapping/a/type")`,
+		`jsl: value of discriminator mapping is not of properties form (at "/properties/kind/mapping/a")`,
+		`jsl: definitions outside of root schema (at "/properties/nested/definitions")`,
+	}, messages)
+
+	assert.Equal(t, errs[0].Err, schema.VerifyRFC8927())
+	assert.Equal(t, 1, len(schema.VerifyAll()))
+}
+
+func TestMarshalRFC8927(t *testing.T) {
+	type testCase struct {
+		in  string
+		out string
+	}
+
+	testCases := []testCase{
+		{`{"type":"number","nullable":true}`, `{"type":"float64","nullable":true}`},
+		{
+			`{"discriminator":{"mapping":{"a":{"properties":{},"additionalProperties":true}},"tag":"t"},"metadata":{}}`,
+			`{"discriminator":"t","mapping":{"a":{"properties":{},"additionalProperties":true}},"metadata":{}}`,
+		},
+		{
+			`{"definitions":{"a":{"values":{"type":"number"}}},"elements":{"ref":"a","definitions":{}}}`,
+			`{"definitions":{"a":{"values":{"type":"float64"}}},"elements":{"ref":"a"}}`,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.in, func(t *testing.T) {
+			var schema jsl.Schema
+			assert.NoError(t, json.Unmarshal([]byte(tt.in), &schema))
+
+			out, err := jsl.MarshalRFC8927(schema)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.out, string(out))
+
+			parsed, err := jsl.ParseRFC8927(out)
+			assert.NoError(t, err)
+
+			again, err := jsl.MarshalRFC8927(parsed)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.out, string(again))
+		})
+	}
+
+	_, err := jsl.MarshalRFC8927(jsl.Schema{
+		OptionalProperties: map[string]jsl.Schema{"id": jsl.Schema{Type: jsl.TypeInt64}},
+	})
+	assert.EqualError(t, err, `jsl: no such type: int64 (at "/optionalProperties/id/type")`)
+}
+
+func TestVerifyCyclicRefs(t *testing.T) {
+	var schema jsl.Schema
+	err := json.Unmarshal([]byte(`{
+		"definitions": {
+			"a": { "ref": "b" },
+			"b": { "ref": "a" },
+			"c": { "ref": "a" },
+			"d": { "ref": "d" },
+			"list": { "elements": { "ref": "list" } }
+		},
+		"ref": "c"
+	}`), &schema)
+	assert.NoError(t, err)
+
+	assert.Equal(t, jsl.ErrCyclicRef("a"), schema.Verify())
+	assert.Equal(t, []*jsl.SchemaError{
+		&jsl.SchemaError{Path: []string{"definitions", "a", "ref"}, Err: jsl.ErrCyclicRef("a")},
+		&jsl.SchemaError{Path: []string{"definitions", "b", "ref"}, Err: jsl.ErrCyclicRef("b")},
+		&jsl.SchemaError{Path: []string{"definitions", "d", "ref"}, Err: jsl.ErrCyclicRef("d")},
+	}, schema.VerifyAll())
+}
+
+func TestUnreachableDefinitions(t *testing.T) {
+	var schema jsl.Schema
+	err := json.Unmarshal([]byte(`{
+		"definitions": {
+			"a": { "properties": { "b": { "ref": "b" } } },
+			"b": { "values": { "ref": "a" } },
+			"c": { "elements": { "ref": "d" } },
+			"d": {},
+			"e": { "discriminator": { "tag": "t", "mapping": { "x": { "optionalProperties": { "f": { "ref": "f" } } } } } },
+			"f": {}
+		},
+		"elements": { "discriminator": { "tag": "t", "mapping": { "x": { "properties": { "a": { "ref": "a" } } } } } }
+	}`), &schema)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"c", "d", "e", "f"}, schema.UnreachableDefinitions())
+	assert.Nil(t, (&jsl.Schema{}).UnreachableDefinitions())
+}
@@ -0,0 +1,322 @@
+package jsl
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+)
+
+// validateStream is the counterpart of validate for instances that have not
+// yet been decoded. It consumes exactly one JSON value from dec.
+//
+// Scalars, and composite values that do not match the form of the schema, are
+// handed over to validate, so that both produce the same errors. Instances of
+// the discriminator form are decoded in full, since the tag may appear after
+// the properties it determines the schema for.
+//
+// nullable is whether null is accepted regardless of n, because n was reached
+// by way of a nullable ref. Since what follows in dec cannot be known before
+// following the ref, it is checked for null only once a token is read.
+func (vm *vm) validateStream(n *node, dec *json.Decoder, parentTag *string, nullable bool) error {
+	nullable = nullable || n.nullable
+
+	switch n.form {
+	case FormEmpty:
+		// Nothing to be done. Empty never fails.
+		_, err := skipValue(dec)
+		return err
+	case FormRef:
+		if len(vm.SchemaTokens) == vm.MaxDepth {
+			return ErrMaxDepthExceeded
+		}
+
+		vm.SchemaTokens = append(vm.SchemaTokens, []string{"definitions", n.refName})
+
+		if err := vm.validateStream(n.ref, dec, nil, nullable); err != nil {
+			return err
+		}
+
+		vm.SchemaTokens = vm.SchemaTokens[:len(vm.SchemaTokens)-1]
+		return nil
+	case FormDiscriminator:
+		var instance interface{}
+		if err := dec.Decode(&instance); err != nil {
+			return err
+		}
+
+		if instance == nil && nullable {
+			return nil
+		}
+
+		return vm.validate(n, instance, parentTag)
+	}
+
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	if token == nil && nullable {
+		return nil
+	}
+
+	switch token {
+	case json.Delim('['):
+		if n.form != FormElements {
+			if err := skipRest(dec); err != nil {
+				return err
+			}
+
+			return vm.validate(n, []interface{}{}, parentTag)
+		}
+
+		vm.pushSchemaToken("elements")
+		for i := 0; dec.More(); i++ {
+			vm.pushInstanceToken(strconv.Itoa(i))
+			if err := vm.validateStream(n.elements, dec, nil, false); err != nil {
+				return err
+			}
+			vm.popInstanceToken()
+		}
+		vm.popSchemaToken()
+
+		_, err := dec.Token()
+		return err
+	case json.Delim('{'):
+		switch n.form {
+		case FormProperties:
+			return vm.validateStreamProperties(n, dec, parentTag)
+		case FormValues:
+			return vm.validateStreamValues(n, dec)
+		default:
+			if err := skipRest(dec); err != nil {
+				return err
+			}
+
+			return vm.validate(n, map[string]interface{}{}, parentTag)
+		}
+	default:
+		return vm.validate(n, token, parentTag)
+	}
+}
+
+// validateStreamValues validates the remainder of an object against a schema
+// of the values form.
+//
+// Under ErrorOrderSchema, the errors for each value are held back until the
+// end of the object, and then reordered by key.
+func (vm *vm) validateStreamValues(n *node, dec *json.Decoder) error {
+	schemaOrder := vm.ErrorOrder == ErrorOrderSchema
+	buckets := map[string][]ValidationError{}
+
+	if schemaOrder {
+		vm.buffering++
+	}
+
+	vm.pushSchemaToken("values")
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		key := token.(string)
+		start := len(vm.Errors)
+
+		vm.pushInstanceToken(key)
+		if err := vm.validateStream(n.values, dec, nil, false); err != nil {
+			return err
+		}
+		vm.popInstanceToken()
+
+		if schemaOrder {
+			buckets[key] = append(buckets[key], vm.takeErrors(start)...)
+		}
+	}
+	vm.popSchemaToken()
+
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+
+	if !schemaOrder {
+		return nil
+	}
+
+	keys := make([]string, 0, len(buckets))
+	for k := range buckets {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	for _, k := range keys {
+		vm.Errors = append(vm.Errors, buckets[k]...)
+	}
+
+	return vm.endBuffering()
+}
+
+// validateStreamProperties validates the remainder of an object against a
+// schema of the properties form.
+//
+// Under ErrorOrderSchema, the errors for each property are held back until the
+// end of the object, and then reordered the same way as in
+// validatePropertiesSchemaOrder.
+func (vm *vm) validateStreamProperties(n *node, dec *json.Decoder, parentTag *string) error {
+	schemaOrder := vm.ErrorOrder == ErrorOrderSchema
+	buckets := map[string][]ValidationError{}
+	seen := make(map[string]struct{}, len(n.required))
+	var unknown []string
+
+	if schemaOrder {
+		vm.buffering++
+	}
+
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		key := token.(string)
+		start := len(vm.Errors)
+
+		if subSchema, ok := n.required[key]; ok {
+			seen[key] = struct{}{}
+
+			vm.pushSchemaToken("properties")
+			vm.pushSchemaToken(key)
+			vm.pushInstanceToken(key)
+			if err := vm.validateStream(subSchema, dec, nil, false); err != nil {
+				return err
+			}
+			vm.popInstanceToken()
+			vm.popSchemaToken()
+			vm.popSchemaToken()
+		} else if subSchema, ok := n.optional[key]; ok {
+			vm.pushSchemaToken("optionalProperties")
+			vm.pushSchemaToken(key)
+			vm.pushInstanceToken(key)
+			if err := vm.validateStream(subSchema, dec, nil, false); err != nil {
+				return err
+			}
+			vm.popInstanceToken()
+			vm.popSchemaToken()
+			vm.popSchemaToken()
+		} else {
+			value, err := skipValue(dec)
+			if err != nil {
+				return err
+			}
+
+			// The same "discriminator tag exemption" as in validate applies here.
+			if vm.bansUnknown(n) && (parentTag == nil || key != *parentTag) {
+				if _, ok := buckets[key]; !ok {
+					unknown = append(unknown, key)
+				}
+
+				vm.pushInstanceToken(key)
+				if err := vm.pushErr(KindUnknownProperty, n.propertyNames, describe(value)); err != nil {
+					return err
+				}
+				vm.popInstanceToken()
+			}
+		}
+
+		if schemaOrder {
+			buckets[key] = append(buckets[key], vm.takeErrors(start)...)
+		}
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+
+	vm.pushSchemaToken("properties")
+	for _, property := range n.requiredNames {
+		vm.Errors = append(vm.Errors, buckets[property]...)
+
+		if _, ok := seen[property]; !ok {
+			vm.pushSchemaToken(property)
+			if err := vm.pushErr(KindMissingProperty, []string{property}, ""); err != nil {
+				return err
+			}
+			vm.popSchemaToken()
+		}
+	}
+	vm.popSchemaToken()
+
+	if !schemaOrder {
+		return nil
+	}
+
+	for _, property := range n.optionalNames {
+		vm.Errors = append(vm.Errors, buckets[property]...)
+	}
+
+	sort.Strings(unknown)
+	for _, k := range unknown {
+		vm.Errors = append(vm.Errors, buckets[k]...)
+	}
+
+	return vm.endBuffering()
+}
+
+// takeErrors removes the errors produced since there were start errors, and
+// returns them.
+func (vm *vm) takeErrors(start int) []ValidationError {
+	errs := append([]ValidationError(nil), vm.Errors[start:]...)
+	vm.Errors = vm.Errors[:start]
+	return errs
+}
+
+// endBuffering undoes an increment of buffering. Once nothing is being held
+// back anymore, MaxErrors is enforced on the errors produced in the meantime.
+func (vm *vm) endBuffering() error {
+	vm.buffering--
+
+	if vm.buffering == 0 && vm.MaxErrors > 0 && len(vm.Errors) >= vm.MaxErrors {
+		vm.Errors = vm.Errors[:vm.MaxErrors]
+		return errMaxErrors
+	}
+
+	return nil
+}
+
+// skipValue consumes one JSON value from dec without decoding it. Scalars are
+// returned as-is, and arrays and objects are returned as empty placeholders.
+func skipValue(dec *json.Decoder) (interface{}, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch token {
+	case json.Delim('['):
+		return []interface{}{}, skipRest(dec)
+	case json.Delim('{'):
+		return map[string]interface{}{}, skipRest(dec)
+	}
+
+	return token, nil
+}
+
+// skipRest consumes the remainder of an array or object whose opening
+// delimiter has already been read.
+func skipRest(dec *json.Decoder) error {
+	for depth := 1; depth > 0; {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		switch token {
+		case json.Delim('['), json.Delim('{'):
+			depth++
+		case json.Delim(']'), json.Delim('}'):
+			depth--
+		}
+	}
+
+	return nil
+}
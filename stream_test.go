@@ -0,0 +1,164 @@
+package jsl_test
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"testing"
+
+	jsl "github.com/json-schema-language/json-schema-language-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func sortValidationErrors(errs []jsl.ValidationError) {
+	sort.Slice(errs, func(i, j int) bool {
+		a := strings.Join(errs[i].SchemaPath, "/") + " " + strings.Join(errs[i].InstancePath, "/")
+		b := strings.Join(errs[j].SchemaPath, "/") + " " + strings.Join(errs[j].InstancePath, "/")
+		return a < b
+	})
+}
+
+func TestValidateBytes(t *testing.T) {
+	type testCase struct {
+		name     string
+		schema   string
+		strict   bool
+		instance string
+	}
+
+	testCases := []testCase{
+		{
+			"empty",
+			`{}`,
+			false,
+			`{"a":[1,{"b":null}]}`,
+		},
+		{
+			"type mismatch on composite",
+			`{"type":"string"}`,
+			false,
+			`[1,2,3]`,
+		},
+		{
+			"elements",
+			`{"elements":{"type":"boolean"}}`,
+			false,
+			`[true,null,{},[],"a",false]`,
+		},
+		{
+			"properties",
+			`{
+				"properties": {
+					"name": {"type": "string"},
+					"age": {"type": "uint8"}
+				},
+				"optionalProperties": {
+					"phones": {"elements": {"type": "string"}}
+				}
+			}`,
+			true,
+			`{"age":"43","extra":{"x":[1]},"phones":["+44 1234567",442345678]}`,
+		},
+		{
+			"values",
+			`{"values":{"enum":["a","b"]}}`,
+			false,
+			`{"x":"a","y":"c","z":{}}`,
+		},
+		{
+			"discriminator",
+			`{
+				"discriminator": {
+					"tag": "type",
+					"mapping": {
+						"a": {"properties": {"x": {"type": "string"}}},
+						"b": {"properties": {"y": {"type": "string"}}}
+					}
+				}
+			}`,
+			true,
+			`[{"x":1,"type":"a"},{"type":"c"},{"type":1},{}]`,
+		},
+		{
+			"refs",
+			`{
+				"definitions": {
+					"node": {
+						"properties": {"value": {"type": "string"}},
+						"optionalProperties": {"next": {"ref": "node"}}
+					}
+				},
+				"ref": "node"
+			}`,
+			false,
+			`{"value":"a","next":{"value":1,"next":{"next":null}}}`,
+		},
+		{
+			"nullable",
+			`{
+				"definitions": {
+					"a": {"properties": {"x": {"type": "string", "nullable": true}}},
+					"b": {"discriminator": {"tag": "t", "mapping": {"b": {"properties": {}}}}, "nullable": true}
+				},
+				"properties": {
+					"a": {"elements": {"ref": "a", "nullable": true}},
+					"b": {"values": {"ref": "b"}},
+					"c": {"ref": "a"}
+				}
+			}`,
+			false,
+			`{"a":[null,{"x":null},{"x":1},1],"b":{"x":null,"y":{"t":"b"},"z":{}},"c":null}`,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			var schema jsl.Schema
+			assert.NoError(t, json.Unmarshal([]byte(tt.schema), &schema))
+
+			// The discriminator case is an array of discriminated objects.
+			if tt.name == "discriminator" {
+				mapping := schema
+				schema = jsl.Schema{Elements: &mapping}
+			}
+
+			var instance interface{}
+			assert.NoError(t, json.Unmarshal([]byte(tt.instance), &instance))
+
+			validator := jsl.Validator{StrictInstanceSemantics: tt.strict}
+
+			expected, err := validator.Validate(schema, instance)
+			assert.NoError(t, err)
+
+			actual, err := validator.ValidateBytes(schema, []byte(tt.instance))
+			assert.NoError(t, err)
+
+			actualReader, err := validator.ValidateReader(schema, strings.NewReader(tt.instance))
+			assert.NoError(t, err)
+
+			sortValidationErrors(expected.Errors)
+			sortValidationErrors(actual.Errors)
+			sortValidationErrors(actualReader.Errors)
+			assert.Equal(t, expected.Errors, actual.Errors)
+			assert.Equal(t, expected.Errors, actualReader.Errors)
+		})
+	}
+}
+
+func TestValidateBytesInvalidJSON(t *testing.T) {
+	validator := jsl.Validator{}
+
+	for _, data := range []string{``, `[1,`, `{"a":}`, `1 2`} {
+		_, err := validator.ValidateBytes(jsl.Schema{}, []byte(data))
+		assert.Error(t, err, data)
+	}
+}
+
+func TestValidateBytesMaxErrors(t *testing.T) {
+	validator := jsl.Validator{MaxErrors: 2}
+	schema := jsl.Schema{Elements: &jsl.Schema{Type: jsl.TypeBoolean}}
+
+	result, err := validator.ValidateReader(schema, strings.NewReader(`[1,2,3,4`))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(result.Errors))
+}
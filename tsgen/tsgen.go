@@ -0,0 +1,320 @@
+// Package tsgen generates TypeScript declarations from JSON Schema Language
+// schemas.
+//
+// The root schema and each definition become an exported type, named after
+// RootName and the name of the definition. Schemas of the properties form
+// become interfaces if they are the root or a definition, and object types
+// otherwise, or Record<string, unknown> if they have no properties. All other
+// schemas become type aliases, or are written out where they are used:
+//
+// Schemas of the enum form become unions of string literals, such as
+// "a" | "b". Nullable schemas become unions with null, such as string | null,
+// and are never interfaces. Schemas of the elements and values form become arrays and
+// Record<string, T>. Schemas of the discriminator form become unions of object
+// types, each with the tag as a string literal property, so that TypeScript
+// can narrow them by the tag.
+//
+// All numeric types become number, and "timestamp" becomes string. The empty
+// form becomes unknown.
+package tsgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	jsl "github.com/json-schema-language/json-schema-language-go"
+)
+
+// Generator generates TypeScript source code from a schema.
+type Generator struct {
+	// RootName is the name of the type generated for the root schema. If empty,
+	// "Root" is used.
+	RootName string
+
+	// Command is the name of the command generating the code, which is
+	// mentioned in the header of the generated file. If empty, "tsgen" is used.
+	Command string
+}
+
+// Generate returns TypeScript source code declaring the types of a schema and
+// its definitions.
+//
+// Generate returns the same errors as Verify if the schema is not correct. It
+// also returns an error if two types would end up with the same name.
+func (g *Generator) Generate(schema jsl.Schema) ([]byte, error) {
+	if err := schema.Verify(); err != nil {
+		return nil, err
+	}
+
+	rootName := g.RootName
+	if rootName == "" {
+		rootName = "Root"
+	}
+
+	defNames := make([]string, 0, len(schema.Definitions))
+	for name := range schema.Definitions {
+		defNames = append(defNames, name)
+	}
+
+	sort.Strings(defNames)
+
+	s := state{defs: make(map[string]string, len(schema.Definitions))}
+	names := map[string]string{rootName: "the root schema"}
+	for _, name := range defNames {
+		tsName := typeName(name)
+		if other, ok := names[tsName]; ok {
+			return nil, fmt.Errorf("tsgen: %s and %s would both be named %s", other, definition(name), tsName)
+		}
+
+		// Record is used in the generated code, and so must not be shadowed.
+		if tsName == "Record" {
+			return nil, fmt.Errorf("tsgen: %s cannot be named Record", definition(name))
+		}
+
+		names[tsName] = definition(name)
+		s.defs[name] = tsName
+	}
+
+	command := g.Command
+	if command == "" {
+		command = "tsgen"
+	}
+
+	fmt.Fprintf(&s.out, "// Code generated by %s. DO NOT EDIT.\n", command)
+
+	s.declare(rootName, "the root schema", &schema)
+	for _, name := range defNames {
+		def := schema.Definitions[name]
+		s.declare(s.defs[name], definition(name), &def)
+	}
+
+	return s.out.Bytes(), nil
+}
+
+// state is the state of a single call to Generate.
+type state struct {
+	// defs maps the names of definitions to the names of their types.
+	defs map[string]string
+	out  bytes.Buffer
+}
+
+// declare writes out an exported declaration named name for a schema.
+func (s *state) declare(name, from string, schema *jsl.Schema) {
+	fmt.Fprintf(&s.out, "\n/** %s is generated from %s. */\n", name, from)
+
+	if len(schema.RequiredProperties)+len(schema.OptionalProperties) > 0 && !schema.Nullable {
+		fmt.Fprintf(&s.out, "export interface %s %s\n", name, s.object(schema, "", false, "", ""))
+		return
+	}
+
+	expr, _ := s.typeExpr(schema, "")
+	fmt.Fprintf(&s.out, "export type %s =%s;\n", name, spaced(expr))
+}
+
+// spaced prefixes expr with a space, unless it starts on a new line.
+func spaced(expr string) string {
+	if strings.HasPrefix(expr, "\n") {
+		return expr
+	}
+
+	return " " + expr
+}
+
+// typeExpr returns the TypeScript type for a schema, and whether that type is a
+// union. Lines after the first are indented by indent.
+func (s *state) typeExpr(schema *jsl.Schema, indent string) (string, bool) {
+	expr, union := s.nonNullTypeExpr(schema, indent)
+	if !schema.Nullable || schema.Form() == jsl.FormEmpty {
+		return expr, union
+	}
+
+	// The variants of a discriminator are each on a line of their own, and so
+	// is null.
+	if schema.Form() == jsl.FormDiscriminator && len(schema.Discriminator.Mapping) > 0 {
+		return expr + "\n" + indent + "  | null", true
+	}
+
+	return expr + " | null", true
+}
+
+// nonNullTypeExpr is like typeExpr, but ignores whether the schema itself is
+// nullable.
+func (s *state) nonNullTypeExpr(schema *jsl.Schema, indent string) (string, bool) {
+	switch schema.Form() {
+	case jsl.FormRef:
+		return s.defs[*schema.Ref], false
+	case jsl.FormType:
+		switch schema.Type {
+		case jsl.TypeBoolean:
+			return "boolean", false
+		case jsl.TypeString, jsl.TypeTimestamp:
+			return "string", false
+		default:
+			return "number", false
+		}
+	case jsl.FormEnum:
+		// An empty union is not valid TypeScript, but "never" is the type that
+		// accepts no values.
+		if len(schema.Enum) == 0 {
+			return "never", false
+		}
+
+		literals := make([]string, len(schema.Enum))
+		for i, val := range schema.Enum {
+			literals[i] = literal(val)
+		}
+
+		return strings.Join(literals, " | "), len(literals) > 1
+	case jsl.FormElements:
+		expr, union := s.typeExpr(schema.Elements, indent)
+		if union {
+			expr = "(" + expr + ")"
+		}
+
+		return expr + "[]", false
+	case jsl.FormProperties:
+		return s.object(schema, indent, false, "", ""), false
+	case jsl.FormValues:
+		expr, _ := s.typeExpr(schema.Values, indent)
+		return "Record<string, " + expr + ">", false
+	case jsl.FormDiscriminator:
+		if len(schema.Discriminator.Mapping) == 0 {
+			return "never", false
+		}
+
+		var b strings.Builder
+		for _, k := range sortedKeys(schema.Discriminator.Mapping) {
+			m := schema.Discriminator.Mapping[k]
+			fmt.Fprintf(&b, "\n%s  | %s", indent, s.object(&m, indent+"    ", true, schema.Discriminator.Tag, k))
+		}
+
+		return b.String(), len(schema.Discriminator.Mapping) > 1
+	default:
+		return "unknown", false
+	}
+}
+
+// object returns an object type for a schema of the properties form. Its
+// members are indented by two spaces more than indent, and the closing brace
+// by indent. If tagged is true, the object has a property named tag whose type
+// is the string literal tagValue. Since "" is a valid tag, tag alone cannot
+// tell whether there is one.
+func (s *state) object(schema *jsl.Schema, indent string, tagged bool, tag, tagValue string) string {
+	var b strings.Builder
+	b.WriteString("{\n")
+
+	if tagged {
+		fmt.Fprintf(&b, "%s  %s: %s;\n", indent, propertyName(tag), literal(tagValue))
+	}
+
+	type property struct {
+		name     string
+		schema   jsl.Schema
+		optional bool
+	}
+
+	var properties []property
+	for k, sub := range schema.RequiredProperties {
+		properties = append(properties, property{name: k, schema: sub})
+	}
+
+	for k, sub := range schema.OptionalProperties {
+		properties = append(properties, property{name: k, schema: sub, optional: true})
+	}
+
+	// Properties the schema does not mention are accepted, so an object with no
+	// properties in particular may still have any.
+	if !tagged && len(properties) == 0 {
+		return "Record<string, unknown>"
+	}
+
+	sort.Slice(properties, func(i, j int) bool {
+		return properties[i].name < properties[j].name
+	})
+
+	for _, p := range properties {
+		optional := ""
+		if p.optional {
+			optional = "?"
+		}
+
+		expr, _ := s.typeExpr(&p.schema, indent+"  ")
+		fmt.Fprintf(&b, "%s  %s%s:%s;\n", indent, propertyName(p.name), optional, spaced(expr))
+	}
+
+	b.WriteString(indent + "}")
+	return b.String()
+}
+
+// literal returns a TypeScript string literal. JSON string literals are valid
+// in TypeScript, including U+2028 and U+2029, which encoding/json escapes.
+func literal(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
+
+var identifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// propertyName returns name as-is if it is an identifier, or as a string
+// literal otherwise.
+func propertyName(name string) string {
+	if identifier.MatchString(name) {
+		return name
+	}
+
+	return literal(name)
+}
+
+// typeName turns the name of a definition into a TypeScript type name. Letters
+// and digits are kept, and every other character starts a new word, which is
+// capitalized.
+func typeName(name string) string {
+	var b strings.Builder
+	upper := true
+
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+
+		if b.Len() == 0 && unicode.IsDigit(r) {
+			b.WriteByte('T')
+		}
+
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+
+		b.WriteRune(r)
+	}
+
+	if b.Len() == 0 {
+		return "Empty"
+	}
+
+	return b.String()
+}
+
+func sortedKeys(schemas map[string]jsl.Schema) []string {
+	keys := make([]string, 0, len(schemas))
+	for k := range schemas {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// definition returns a JSON Pointer to the definition of the given name.
+func definition(name string) string {
+	return "/definitions/" + pointerEscaper.Replace(name)
+}
@@ -0,0 +1,197 @@
+package tsgen_test
+
+import (
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	jsl "github.com/json-schema-language/json-schema-language-go"
+	"github.com/json-schema-language/json-schema-language-go/tsgen"
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+func TestGenerate(t *testing.T) {
+	var schema jsl.Schema
+	err := json.Unmarshal([]byte(`{
+		"definitions": {
+			"user_address": {
+				"properties": {
+					"street": { "type": "string" },
+					"zip": { "type": "uint32" }
+				}
+			},
+			"event": {
+				"discriminator": {
+					"tag": "type",
+					"mapping": {
+						"created": {
+							"properties": { "at": { "type": "timestamp" } }
+						},
+						"renamed": {
+							"properties": { "name": { "type": "string" } },
+							"optionalProperties": { "reason": { "type": "string" } }
+						}
+					}
+				}
+			},
+			"status": { "enum": ["active", "on-hold"] },
+			"any": {},
+			"nothing": { "properties": {} },
+			"deletion": { "properties": { "at": { "type": "timestamp" } }, "nullable": true }
+		},
+		"properties": {
+			"name": { "type": "string" },
+			"status": { "ref": "status" },
+			"flags": { "elements": { "enum": ["a", "b"] } },
+			"addresses": { "elements": { "ref": "user_address" } },
+			"events": { "values": { "ref": "event" } },
+			"content-type": { "type": "string" },
+			"nickname": { "type": "string", "nullable": true },
+			"scores": { "elements": { "type": "float64", "nullable": true } },
+			"deleted": { "ref": "deletion" },
+			"manager": {
+				"properties": {
+					"id": { "type": "int64" },
+					"active": { "type": "boolean" }
+				}
+			},
+			"shape": {
+				"nullable": true,
+				"discriminator": {
+					"tag": "kind",
+					"mapping": {
+						"circle": { "properties": { "radius": { "type": "float64" } } }
+					}
+				}
+			}
+		},
+		"optionalProperties": {
+			"age": { "type": "uint8" },
+			"tags": { "elements": { "type": "string" } }
+		}
+	}`), &schema)
+	assert.NoError(t, err)
+
+	g := tsgen.Generator{RootName: "User"}
+	out, err := g.Generate(schema)
+	assert.NoError(t, err)
+
+	if *update {
+		assert.NoError(t, ioutil.WriteFile("testdata/user.ts.golden", out, 0644))
+	}
+
+	golden, err := ioutil.ReadFile("testdata/user.ts.golden")
+	assert.NoError(t, err)
+	assert.Equal(t, string(golden), string(out))
+}
+
+func TestGenerateEmptyUnions(t *testing.T) {
+	type testCase struct {
+		name string
+		in   string
+		out  string
+	}
+
+	testCases := []testCase{
+		{"enum", `{"enum":[]}`, "export type Root = never;\n"},
+		{"nullable enum", `{"enum":[],"nullable":true}`, "export type Root = never | null;\n"},
+		{"discriminator", `{"discriminator":{"tag":"t","mapping":{}}}`, "export type Root = never;\n"},
+		{
+			"nullable discriminator",
+			`{"discriminator":{"tag":"t","mapping":{}},"nullable":true}`,
+			"export type Root = never | null;\n",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			var schema jsl.Schema
+			assert.NoError(t, json.Unmarshal([]byte(tt.in), &schema))
+
+			g := tsgen.Generator{}
+			out, err := g.Generate(schema)
+			assert.NoError(t, err)
+			assert.True(t, strings.HasSuffix(string(out), tt.out), string(out))
+		})
+	}
+}
+
+func TestGenerateEmptyObjects(t *testing.T) {
+	type testCase struct {
+		name string
+		in   string
+		out  string
+	}
+
+	testCases := []testCase{
+		{"no properties", `{"properties":{}}`, "export type Root = Record<string, unknown>;\n"},
+		{
+			"nested no properties",
+			`{"properties":{"a":{"optionalProperties":{}}}}`,
+			"export interface Root {\n  a: Record<string, unknown>;\n}\n",
+		},
+		{
+			"empty tag",
+			`{"discriminator":{"tag":"","mapping":{"a":{"properties":{}}}}}`,
+			"export type Root =\n  | {\n      \"\": \"a\";\n    };\n",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			var schema jsl.Schema
+			assert.NoError(t, json.Unmarshal([]byte(tt.in), &schema))
+
+			g := tsgen.Generator{}
+			out, err := g.Generate(schema)
+			assert.NoError(t, err)
+			assert.True(t, strings.HasSuffix(string(out), tt.out), string(out))
+		})
+	}
+}
+
+func TestGenerateInvalid(t *testing.T) {
+	type testCase struct {
+		name string
+		in   string
+		err  string
+	}
+
+	testCases := []testCase{
+		{
+			"incorrect schema",
+			`{"ref":"a"}`,
+			"jsl: no such definition: a",
+		},
+		{
+			"name collision",
+			`{"definitions":{"a_b":{},"a/b":{}}}`,
+			"tsgen: /definitions/a~1b and /definitions/a_b would both be named AB",
+		},
+		{
+			"root name collision",
+			`{"definitions":{"root":{}}}`,
+			"tsgen: the root schema and /definitions/root would both be named Root",
+		},
+		{
+			"shadowing Record",
+			`{"definitions":{"record":{}}}`,
+			"tsgen: /definitions/record cannot be named Record",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			var schema jsl.Schema
+			assert.NoError(t, json.Unmarshal([]byte(tt.in), &schema))
+
+			g := tsgen.Generator{}
+			_, err := g.Generate(schema)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
@@ -1,5 +1,12 @@
 package jsl
 
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
 // Validator validates instances ("inputs") against schemas.
 //
 // When evaluating untrusted schemas, always set MaxDepth to a nonzero value.
@@ -23,9 +30,60 @@ type Validator struct {
 	// Whether to enforce strict instance semantics. See the spec for a formal
 	// definition, but essentially, strict instance semantics bans "unknown" or
 	// "unspecified" properties from appearing in instances.
+	//
+	// To remove such properties instead of reporting them, see Prune.
 	StrictInstanceSemantics bool
+
+	// Whether to follow RFC 8927 (JSON Type Definition) instead of the JSL
+	// spec. Under RFC 8927, unknown properties are always banned, unless the
+	// schema sets AdditionalProperties, and so StrictInstanceSemantics has no
+	// effect. Errors for the discriminator form have the schema paths given by
+	// RFC 8927, where "mapping" is a keyword of its own, and timestamps may
+	// have a leap second.
+	//
+	// Schemas should be checked with VerifyRFC8927 rather than Verify.
+	RFC8927 bool
+
+	// Whether to check that instances of TypeFloat32 are within the range of
+	// finite float32 values. By default, float32 is treated like TypeNumber, and
+	// so numbers that would overflow to infinity when converted to a float32 are
+	// accepted. Such numbers produce errors of KindFloat32Range.
+	StrictFloat32 bool
+
+	// Whether to additionally check that instances of TypeFloat32 are exactly
+	// representable as a float32, such that no precision is lost by converting
+	// them. Numbers like 0.1 are not. Such numbers produce errors of
+	// KindFloat32Precision. ExactFloat32 implies StrictFloat32.
+	ExactFloat32 bool
+
+	// The order in which to produce errors. Errors are always produced in a
+	// deterministic order, and when MaxErrors is set, it is the first errors in
+	// this order that are produced.
+	//
+	// The zero value is ErrorOrderSchema.
+	ErrorOrder ErrorOrder
 }
 
+// ErrorOrder represents the orders in which a Validator may produce errors.
+type ErrorOrder int
+
+const (
+	// ErrorOrderSchema orders errors by where they arise in the schema. The
+	// properties of an object are visited in this order: required properties
+	// sorted by name, then optional properties sorted by name, and finally, under
+	// strict instance semantics, unknown properties sorted by name. The values
+	// of an object of the values form are visited sorted by key.
+	ErrorOrderSchema ErrorOrder = iota
+
+	// ErrorOrderInstance orders errors by where they arise in the instance. The
+	// elements of arrays are visited in order. The properties of objects are
+	// visited in the order they appear in the input to ValidateReader and
+	// ValidateBytes, and sorted by name otherwise, since Go maps are unordered.
+	// Missing required properties are reported after all other errors for the
+	// object they are missing from.
+	ErrorOrderInstance
+)
+
 // ValidationResult is the set of validation errors arising from running
 // Validate.
 type ValidationResult struct {
@@ -47,10 +105,141 @@ func (r *ValidationResult) IsValid() bool {
 // See the spec for a formal definition of how InstancePath and SchemaPath are
 // computed.
 //
+// Kind, Expected and Actual are not part of the spec. They describe the
+// problem in more detail, so that it can be explained without consulting the
+// schema.
+//
 // Note that this is not a error in the Golang sense of the term.
 type ValidationError struct {
 	InstancePath []string
 	SchemaPath   []string
+
+	// Kind is the kind of problem that was found with the instance.
+	Kind Kind
+
+	// Expected is what the schema required. Its contents depend on Kind:
+	//
+	// For KindType, KindFloat32Range and KindFloat32Precision, it is the type
+	// from the schema, such as "int8".
+	//
+	// For KindEnum, it is the values of the enum, sorted.
+	//
+	// For KindElements, it is "array". For KindProperties, KindValues and
+	// KindDiscriminator, it is "object". For KindTagType, it is "string".
+	//
+	// For KindMissingProperty, it is the name of the missing property, and for
+	// KindMissingTag, the name of the tag.
+	//
+	// For KindUnknownProperty, it is the names of the properties the schema
+	// allows, sorted. For KindUnmappedTag, it is the keys of the mapping, sorted.
+	Expected []string
+
+	// Actual is a rendering of the part of the instance at InstancePath, for
+	// display purposes. Scalars are rendered as JSON, with long strings and
+	// numbers truncated. Arrays and objects are rendered as "[...]" and "{...}".
+	//
+	// Actual is empty for KindMissingProperty and KindMissingTag, since there is
+	// nothing there to render.
+	Actual string
+}
+
+// Kind represents the kinds of problems that a ValidationError may report.
+type Kind int
+
+const (
+	// KindType represents an instance that is not of the type required by a
+	// schema of the type form. This includes numbers that are out of range for
+	// an integer type, and strings that are not timestamps.
+	KindType Kind = iota + 1
+
+	// KindFloat32Range represents a number that is out of range for a float32.
+	// See StrictFloat32 on Validator.
+	KindFloat32Range
+
+	// KindFloat32Precision represents a number that cannot be represented
+	// exactly as a float32. See ExactFloat32 on Validator.
+	KindFloat32Precision
+
+	// KindEnum represents an instance that is not one of the values of an enum.
+	KindEnum
+
+	// KindElements represents an instance that is not an array.
+	KindElements
+
+	// KindProperties represents an instance that is not an object, for a schema
+	// of the properties form.
+	KindProperties
+
+	// KindMissingProperty represents an object missing a required property.
+	KindMissingProperty
+
+	// KindUnknownProperty represents an object with a property that is neither
+	// required nor optional, under strict instance semantics or RFC 8927.
+	KindUnknownProperty
+
+	// KindValues represents an instance that is not an object, for a schema of
+	// the values form.
+	KindValues
+
+	// KindDiscriminator represents an instance that is not an object, for a
+	// schema of the discriminator form.
+	KindDiscriminator
+
+	// KindMissingTag represents an object missing its discriminator tag.
+	KindMissingTag
+
+	// KindTagType represents an object whose discriminator tag is not a string.
+	KindTagType
+
+	// KindUnmappedTag represents an object whose discriminator tag is not one
+	// of the values in the mapping.
+	KindUnmappedTag
+)
+
+var kindNames = map[Kind]string{
+	KindType:             "type",
+	KindFloat32Range:     "float32Range",
+	KindFloat32Precision: "float32Precision",
+	KindEnum:             "enum",
+	KindElements:         "elements",
+	KindProperties:       "properties",
+	KindMissingProperty:  "missingProperty",
+	KindUnknownProperty:  "unknownProperty",
+	KindValues:           "values",
+	KindDiscriminator:    "discriminator",
+	KindMissingTag:       "missingTag",
+	KindTagType:          "tagType",
+	KindUnmappedTag:      "unmappedTag",
+}
+
+// String returns the name of a Kind, such as "missingProperty".
+func (k Kind) String() string {
+	if name, ok := kindNames[k]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// MarshalText encodes a Kind as its name, so that it is readable in JSON.
+func (k Kind) MarshalText() ([]byte, error) {
+	if _, ok := kindNames[k]; !ok {
+		return nil, ErrInvalidKind(k.String())
+	}
+
+	return []byte(k.String()), nil
+}
+
+// UnmarshalText decodes a Kind from its name.
+func (k *Kind) UnmarshalText(text []byte) error {
+	for kind, name := range kindNames {
+		if name == string(text) {
+			*k = kind
+			return nil
+		}
+	}
+
+	return ErrInvalidKind(text)
 }
 
 // Validate checks whether an instance ("input") is valid against a Schema, and
@@ -62,19 +251,102 @@ type ValidationError struct {
 //
 // ErrMaxDepthExceeded is returned if the maximum depth is exceeded. See
 // MaxDepth on Validator for more details.
+//
+// The instance is typically the result of decoding JSON into an interface{},
+// but it may be any Go value that encoding/json could marshal. Such values are
+// validated as if they had been marshaled to JSON first: "json" struct tags,
+// json.Marshaler and encoding.TextMarshaler are all honored. ErrUnsupportedType
+// is returned if the instance contains a value that has no JSON equivalent.
+//
+// Integer types are checked exactly for Go integers and json.Number. Numbers
+// decoded into a float64, which is what encoding/json does by default, may
+// already have been rounded if their magnitude exceeds 2^53, and so checks on
+// TypeInt64 and TypeUint64 are only as exact as that float64: for instance,
+// 9223372036854775808 decodes to the same float64 as the largest int64, and is
+// accepted as an int64. To avoid this, decode instances with json.Decoder's
+// UseNumber, or use ValidateBytes or ValidateReader.
+//
+// Validate prepares the schema anew on every call. When validating many
+// instances against the same schema, use Compile and ValidateCompiled instead.
 func (v *Validator) Validate(schema Schema, instance interface{}) (ValidationResult, error) {
-	vm := vm{
-		MaxErrors:               v.MaxErrors,
-		MaxDepth:                v.MaxDepth,
-		StrictInstanceSemantics: v.StrictInstanceSemantics,
-		RootSchema:              schema,
-		InstanceTokens:          []string{},
-		SchemaTokens:            [][]string{[]string{}},
+	return v.ValidateCompiled(compile(schema), instance)
+}
+
+// ValidateCompiled is like Validate, but evaluates a schema previously
+// prepared with Compile.
+//
+// A Validator and CompiledSchema may be shared between goroutines calling
+// ValidateCompiled concurrently.
+func (v *Validator) ValidateCompiled(schema *CompiledSchema, instance interface{}) (ValidationResult, error) {
+	vm := v.newVM()
+
+	if err := vm.validate(schema.root, instance, nil); err != nil && err != errMaxErrors {
+		return ValidationResult{}, err
 	}
 
-	if err := vm.validate(schema, instance, nil); err != nil && err != errMaxErrors {
+	return ValidationResult{Errors: vm.Errors}, nil
+}
+
+// ValidateReader is like Validate, but reads a single JSON value from r and
+// validates it as it is decoded, without first building it up in memory.
+//
+// Numbers are checked against their exact decimal representation in r, so
+// integer types like TypeInt64 and TypeUint64 are checked without any loss of
+// precision.
+//
+// Errors from reading or decoding r are returned as-is. Once MaxErrors is
+// reached, ValidateReader stops reading, and so syntax errors later in r are
+// not reported. Note that under ErrorOrderSchema, the properties of an object
+// may appear in r in a different order than their errors are produced in, and
+// so MaxErrors can only take effect once the whole object has been read.
+func (v *Validator) ValidateReader(schema Schema, r io.Reader) (ValidationResult, error) {
+	return v.ValidateCompiledReader(compile(schema), r)
+}
+
+// ValidateCompiledReader is like ValidateReader, but evaluates a schema
+// previously prepared with Compile.
+func (v *Validator) ValidateCompiledReader(schema *CompiledSchema, r io.Reader) (ValidationResult, error) {
+	vm := v.newVM()
+
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	if err := vm.validateStream(schema.root, dec, nil, false); err != nil && err != errMaxErrors {
 		return ValidationResult{}, err
 	}
 
 	return ValidationResult{Errors: vm.Errors}, nil
 }
+
+// ValidateBytes is like ValidateReader, but validates the JSON document in
+// data. Unlike ValidateReader, an error is always returned if data is not
+// exactly one valid JSON value.
+func (v *Validator) ValidateBytes(schema Schema, data []byte) (ValidationResult, error) {
+	return v.ValidateCompiledBytes(compile(schema), data)
+}
+
+// ValidateCompiledBytes is like ValidateBytes, but evaluates a schema
+// previously prepared with Compile.
+func (v *Validator) ValidateCompiledBytes(schema *CompiledSchema, data []byte) (ValidationResult, error) {
+	if !json.Valid(data) {
+		// Let encoding/json describe what is wrong with the input.
+		var instance interface{}
+		return ValidationResult{}, json.Unmarshal(data, &instance)
+	}
+
+	return v.ValidateCompiledReader(schema, bytes.NewReader(data))
+}
+
+func (v *Validator) newVM() vm {
+	return vm{
+		MaxErrors:               v.MaxErrors,
+		MaxDepth:                v.MaxDepth,
+		StrictInstanceSemantics: v.StrictInstanceSemantics,
+		RFC8927:                 v.RFC8927,
+		StrictFloat32:           v.StrictFloat32,
+		ExactFloat32:            v.ExactFloat32,
+		ErrorOrder:              v.ErrorOrder,
+		InstanceTokens:          []string{},
+		SchemaTokens:            [][]string{[]string{}},
+	}
+}
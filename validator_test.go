@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 	"testing"
 
 	"github.com/dolmen-go/jsonptr"
@@ -84,6 +85,25 @@ func TestSpec(t *testing.T) {
 							sortErrors(instance.Errors)
 							sortErrors(errors)
 							assert.Equal(t, instance.Errors, errors)
+
+							// Validating the same instance from its JSON encoding must produce
+							// the same errors.
+							data, err := json.Marshal(instance.Instance)
+							assert.NoError(t, err)
+
+							result, err = validator.ValidateBytes(tt.Schema, data)
+							assert.NoError(t, err)
+
+							errors = make([]validationError, len(result.Errors))
+							for i, err := range result.Errors {
+								errors[i] = validationError{
+									InstancePath: jsonptr.Pointer(err.InstancePath).String(),
+									SchemaPath:   jsonptr.Pointer(err.SchemaPath).String(),
+								}
+							}
+
+							sortErrors(errors)
+							assert.Equal(t, instance.Errors, errors)
 						})
 					}
 				})
@@ -115,3 +135,394 @@ func TestMaxDepth(t *testing.T) {
 	_, err := validator.Validate(schema, nil)
 	assert.Equal(t, err, jsl.ErrMaxDepthExceeded)
 }
+
+func TestNullable(t *testing.T) {
+	type testCase struct {
+		name     string
+		schema   string
+		instance string
+		errors   []string
+	}
+
+	testCases := []testCase{
+		{"type", `{"type":"string","nullable":true}`, `null`, nil},
+		{"not nullable", `{"type":"string"}`, `null`, []string{""}},
+		{"enum", `{"enum":["a"],"nullable":true}`, `null`, nil},
+		{"elements", `{"elements":{"type":"int8","nullable":true},"nullable":true}`, `[1,null,"a"]`, []string{"/2"}},
+		{"properties", `{"properties":{"a":{"type":"boolean","nullable":true}},"nullable":true}`, `{"a":null}`, nil},
+		{"values", `{"values":{"type":"boolean"},"nullable":true}`, `null`, nil},
+		{"ref", `{"definitions":{"a":{"type":"string"}},"elements":{"ref":"a","nullable":true}}`, `[null,"a",1]`, []string{"/2"}},
+		{"ref to nullable", `{"definitions":{"a":{"type":"string","nullable":true}},"ref":"a"}`, `null`, nil},
+		{"discriminator", `{"discriminator":{"tag":"t","mapping":{"a":{"properties":{}}}},"nullable":true}`, `null`, nil},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			var schema jsl.Schema
+			assert.NoError(t, json.Unmarshal([]byte(tt.schema), &schema))
+			assert.NoError(t, schema.Verify())
+
+			var instance interface{}
+			assert.NoError(t, json.Unmarshal([]byte(tt.instance), &instance))
+
+			validator := jsl.Validator{}
+			result, err := validator.Validate(schema, instance)
+			assert.NoError(t, err)
+
+			resultBytes, err := validator.ValidateBytes(schema, []byte(tt.instance))
+			assert.NoError(t, err)
+			assert.Equal(t, result, resultBytes)
+
+			var paths []string
+			for _, err := range result.Errors {
+				paths = append(paths, jsonptr.Pointer(err.InstancePath).String())
+			}
+
+			assert.Equal(t, tt.errors, paths)
+		})
+	}
+}
+
+func TestRFC8927(t *testing.T) {
+	type testCase struct {
+		name     string
+		schema   string
+		instance string
+		errors   []string
+	}
+
+	testCases := []testCase{
+		{"unknown property", `{"properties":{"a":{}}}`, `{"a":1,"b":2}`, []string{"/b "}},
+		{"additionalProperties", `{"properties":{"a":{}},"additionalProperties":true}`, `{"a":1,"b":2}`, nil},
+		{"missing property", `{"optionalProperties":{"a":{}},"properties":{"b":{}}}`, `{}`, []string{" /properties/b"}},
+		{"not a discriminator", `{"discriminator":"t","mapping":{}}`, `[]`, []string{" /discriminator"}},
+		{"missing tag", `{"discriminator":"t","mapping":{}}`, `{}`, []string{" /discriminator"}},
+		{"tag type", `{"discriminator":"t","mapping":{}}`, `{"t":1}`, []string{"/t /discriminator"}},
+		{"unmapped tag", `{"discriminator":"t","mapping":{}}`, `{"t":"a"}`, []string{"/t /mapping"}},
+		{
+			"mapping",
+			`{"discriminator":"t","mapping":{"a":{"properties":{"b":{"type":"string"}}}}}`,
+			`{"t":"a","b":1,"c":2}`,
+			[]string{"/b /mapping/a/properties/b/type", "/c /mapping/a"},
+		},
+		{"leap second", `{"type":"timestamp"}`, `"1990-12-31T15:59:60-08:00"`, nil},
+		{"not a leap second", `{"type":"timestamp"}`, `"1990-12-31T15:59:61-08:00"`, []string{" /type"}},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, err := jsl.ParseRFC8927([]byte(tt.schema))
+			assert.NoError(t, err)
+			assert.NoError(t, schema.VerifyRFC8927())
+
+			var instance interface{}
+			assert.NoError(t, json.Unmarshal([]byte(tt.instance), &instance))
+
+			validator := jsl.Validator{RFC8927: true}
+			result, err := validator.Validate(schema, instance)
+			assert.NoError(t, err)
+
+			resultBytes, err := validator.ValidateBytes(schema, []byte(tt.instance))
+			assert.NoError(t, err)
+			assert.Equal(t, result, resultBytes)
+
+			var indicators []string
+			for _, err := range result.Errors {
+				indicators = append(indicators, jsonptr.Pointer(err.InstancePath).String()+" "+jsonptr.Pointer(err.SchemaPath).String())
+			}
+
+			assert.Equal(t, tt.errors, indicators)
+		})
+	}
+}
+
+func TestValidateExactIntegers(t *testing.T) {
+	type testCase struct {
+		typ   jsl.Type
+		in    string
+		valid bool
+	}
+
+	testCases := []testCase{
+		{jsl.TypeInt64, "9223372036854775807", true},
+		{jsl.TypeInt64, "9223372036854775808", false},
+		{jsl.TypeInt64, "-9223372036854775808", true},
+		{jsl.TypeInt64, "-9223372036854775809", false},
+		{jsl.TypeUint64, "18446744073709551615", true},
+		{jsl.TypeUint64, "18446744073709551616", false},
+		{jsl.TypeUint64, "-1", false},
+		{jsl.TypeUint64, "1e20", false},
+		{jsl.TypeUint64, "1e3", true},
+		{jsl.TypeInt8, "127.0", true},
+		{jsl.TypeInt8, "127.5", false},
+		{jsl.TypeInt8, "-128", true},
+		{jsl.TypeInt8, "-1.28e2", true},
+		{jsl.TypeUint8, "256", false},
+		{jsl.TypeInt32, "2147483648", false},
+	}
+
+	validator := jsl.Validator{}
+	for _, tt := range testCases {
+		t.Run(fmt.Sprintf("%s/%s", tt.typ, tt.in), func(t *testing.T) {
+			schema := jsl.Schema{Type: tt.typ}
+
+			result, err := validator.ValidateBytes(schema, []byte(tt.in))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.valid, result.IsValid())
+
+			result, err = validator.Validate(schema, json.Number(tt.in))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.valid, result.IsValid())
+		})
+	}
+}
+
+func TestValidateFloat32(t *testing.T) {
+	type testCase struct {
+		in     string
+		strict jsl.Kind
+		exact  jsl.Kind
+	}
+
+	testCases := []testCase{
+		{"0", 0, 0},
+		{"0.5", 0, 0},
+		{"0.1", 0, jsl.KindFloat32Precision},
+		{"16777216", 0, 0},
+		{"16777217", 0, jsl.KindFloat32Precision},
+		{"340282346638528859811704183484516925440", 0, 0},
+		{"-340282346638528859811704183484516925440", 0, 0},
+		{"1e39", jsl.KindFloat32Range, jsl.KindFloat32Range},
+		{"-1e300", jsl.KindFloat32Range, jsl.KindFloat32Range},
+		{`"1"`, jsl.KindType, jsl.KindType},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.in, func(t *testing.T) {
+			schema := jsl.Schema{Type: jsl.TypeFloat32}
+
+			for _, validator := range []jsl.Validator{
+				jsl.Validator{},
+				jsl.Validator{StrictFloat32: true},
+				jsl.Validator{ExactFloat32: true},
+			} {
+				expected := tt.strict
+				if validator.ExactFloat32 {
+					expected = tt.exact
+				} else if !validator.StrictFloat32 && expected != jsl.KindType {
+					expected = 0
+				}
+
+				var instance interface{}
+				assert.NoError(t, json.Unmarshal([]byte(tt.in), &instance))
+
+				for _, f := range []func() (jsl.ValidationResult, error){
+					func() (jsl.ValidationResult, error) { return validator.ValidateBytes(schema, []byte(tt.in)) },
+					func() (jsl.ValidationResult, error) { return validator.Validate(schema, instance) },
+				} {
+					result, err := f()
+					assert.NoError(t, err)
+
+					if expected == 0 {
+						assert.True(t, result.IsValid())
+					} else {
+						assert.Equal(t, 1, len(result.Errors))
+						assert.Equal(t, []string{"type"}, result.Errors[0].SchemaPath)
+						assert.Equal(t, expected, result.Errors[0].Kind)
+						assert.Equal(t, []string{"float32"}, result.Errors[0].Expected)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestValidationErrorDetails(t *testing.T) {
+	schema := jsl.Schema{
+		Elements: &jsl.Schema{
+			Discriminator: jsl.Discriminator{
+				Tag: "type",
+				Mapping: map[string]jsl.Schema{
+					"b": jsl.Schema{
+						RequiredProperties: map[string]jsl.Schema{
+							"x": jsl.Schema{Enum: []string{"y", "x"}},
+						},
+					},
+					"a": jsl.Schema{
+						OptionalProperties: map[string]jsl.Schema{
+							"y": jsl.Schema{Type: jsl.TypeString},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	instance := `[
+		{"type": "a", "y": "` + strings.Repeat("é", 40) + `", "z": [1]},
+		{"type": "b", "x": "z"},
+		{"type": "b"},
+		{"type": "c"},
+		{"type": false},
+		{},
+		1
+	]`
+
+	validator := jsl.Validator{StrictInstanceSemantics: true}
+	result, err := validator.ValidateBytes(schema, []byte(instance))
+	assert.NoError(t, err)
+
+	type detail struct {
+		Kind     jsl.Kind
+		Expected []string
+		Actual   string
+	}
+
+	details := make([]detail, len(result.Errors))
+	for i, err := range result.Errors {
+		details[i] = detail{err.Kind, err.Expected, err.Actual}
+	}
+
+	assert.Equal(t, []detail{
+		{jsl.KindUnknownProperty, []string{"y"}, "[...]"},
+		{jsl.KindEnum, []string{"x", "y"}, `"z"`},
+		{jsl.KindMissingProperty, []string{"x"}, ""},
+		{jsl.KindUnmappedTag, []string{"a", "b"}, `"c"`},
+		{jsl.KindTagType, []string{"string"}, "false"},
+		{jsl.KindMissingTag, []string{"type"}, ""},
+		{jsl.KindDiscriminator, []string{"object"}, "1"},
+	}, details)
+
+	// The string "y" is valid, but it would be truncated like so.
+	result, err = validator.Validate(jsl.Schema{Type: jsl.TypeBoolean}, strings.Repeat("é", 40))
+	assert.NoError(t, err)
+	assert.Equal(t, `"`+strings.Repeat("é", 32)+`..."`, result.Errors[0].Actual)
+}
+
+func TestKindText(t *testing.T) {
+	data, err := json.Marshal(jsl.ValidationError{Kind: jsl.KindUnmappedTag})
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"Kind":"unmappedTag"`)
+
+	var out jsl.ValidationError
+	assert.NoError(t, json.Unmarshal(data, &out))
+	assert.Equal(t, jsl.KindUnmappedTag, out.Kind)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"Kind":"nonsense"}`), &out))
+}
+
+func TestErrorOrder(t *testing.T) {
+	schema := jsl.Schema{
+		RequiredProperties: map[string]jsl.Schema{
+			"b": jsl.Schema{Type: jsl.TypeString},
+			"d": jsl.Schema{Type: jsl.TypeString},
+			"a": jsl.Schema{Values: &jsl.Schema{Type: jsl.TypeString}},
+		},
+		OptionalProperties: map[string]jsl.Schema{
+			"c": jsl.Schema{Elements: &jsl.Schema{Type: jsl.TypeString}},
+		},
+	}
+
+	// The keys here are in sorted order, so that document order and the sorted
+	// order used for decoded instances agree.
+	instanceJSON := `{"a":{"x":1,"y":2},"b":1,"c":[1,"",2],"e":1,"f":2}`
+
+	var instance interface{}
+	assert.NoError(t, json.Unmarshal([]byte(instanceJSON), &instance))
+
+	type testCase struct {
+		order  jsl.ErrorOrder
+		errors []string
+	}
+
+	testCases := []testCase{
+		{
+			jsl.ErrorOrderSchema,
+			[]string{"/a/x", "/a/y", "/b", "missing d", "/c/0", "/c/2", "/e", "/f"},
+		},
+		{
+			jsl.ErrorOrderInstance,
+			[]string{"/a/x", "/a/y", "/b", "/c/0", "/c/2", "/e", "/f", "missing d"},
+		},
+	}
+
+	for _, tt := range testCases {
+		for maxErrors := 0; maxErrors <= len(tt.errors); maxErrors++ {
+			t.Run(fmt.Sprintf("%d/%d", tt.order, maxErrors), func(t *testing.T) {
+				validator := jsl.Validator{
+					MaxErrors:               maxErrors,
+					StrictInstanceSemantics: true,
+					ErrorOrder:              tt.order,
+				}
+
+				expected := tt.errors
+				if maxErrors != 0 {
+					expected = expected[:maxErrors]
+				}
+
+				summarize := func(result jsl.ValidationResult) []string {
+					out := make([]string, len(result.Errors))
+					for i, err := range result.Errors {
+						if err.Kind == jsl.KindMissingProperty {
+							out[i] = "missing " + err.Expected[0]
+						} else {
+							out[i] = jsonptr.Pointer(err.InstancePath).String()
+						}
+					}
+
+					return out
+				}
+
+				for i := 0; i < 10; i++ {
+					result, err := validator.Validate(schema, instance)
+					assert.NoError(t, err)
+					assert.Equal(t, expected, summarize(result))
+				}
+
+				result, err := validator.ValidateBytes(schema, []byte(instanceJSON))
+				assert.NoError(t, err)
+				assert.Equal(t, expected, summarize(result))
+			})
+		}
+	}
+}
+
+func TestErrorOrderStream(t *testing.T) {
+	schema := jsl.Schema{
+		Values: &jsl.Schema{
+			RequiredProperties: map[string]jsl.Schema{
+				"a": jsl.Schema{Type: jsl.TypeString},
+				"b": jsl.Schema{Type: jsl.TypeString},
+			},
+		},
+	}
+
+	instance := `{"y":{"z":1,"b":1,"a":1},"x":{"b":1}}`
+
+	pointers := func(result jsl.ValidationResult) []string {
+		out := make([]string, len(result.Errors))
+		for i, err := range result.Errors {
+			out[i] = jsonptr.Pointer(err.InstancePath).String() + " " + jsonptr.Pointer(err.SchemaPath).String()
+		}
+
+		return out
+	}
+
+	validator := jsl.Validator{StrictInstanceSemantics: true, MaxErrors: 3}
+	result, err := validator.ValidateBytes(schema, []byte(instance))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"/x /values/properties/a",
+		"/x/b /values/properties/b/type",
+		"/y/a /values/properties/a/type",
+	}, pointers(result))
+
+	validator.ErrorOrder = jsl.ErrorOrderInstance
+	result, err = validator.ValidateBytes(schema, []byte(instance))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"/y/z /values",
+		"/y/b /values/properties/b/type",
+		"/y/a /values/properties/a/type",
+	}, pointers(result))
+}
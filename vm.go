@@ -1,8 +1,11 @@
 package jsl
 
 import (
+	"encoding/json"
 	"errors"
 	"math"
+	"math/big"
+	"sort"
 	"strconv"
 	"time"
 )
@@ -11,16 +14,34 @@ type vm struct {
 	MaxErrors               int
 	MaxDepth                int
 	StrictInstanceSemantics bool
-	RootSchema              Schema
+	RFC8927                 bool
+	StrictFloat32           bool
+	ExactFloat32            bool
+	ErrorOrder              ErrorOrder
 	InstanceTokens          []string
 	SchemaTokens            [][]string
 	Errors                  []ValidationError
+
+	// buffering is nonzero while errors are being produced out of order, and
+	// will be reordered later. MaxErrors is not enforced in the meantime.
+	buffering int
 }
 
 var errMaxErrors = errors.New("jsl internal: max errors reached")
 
-func (vm *vm) validate(schema Schema, instance interface{}, parentTag *string) error {
-	switch schema.Form() {
+func (vm *vm) validate(n *node, instance interface{}, parentTag *string) error {
+	if n.form != FormEmpty {
+		var err error
+		if instance, err = normalize(instance); err != nil {
+			return err
+		}
+	}
+
+	if instance == nil && n.nullable {
+		return nil
+	}
+
+	switch n.form {
 	case FormEmpty:
 		// Nothing to be done. Empty never fails.
 	case FormRef:
@@ -28,84 +49,79 @@ func (vm *vm) validate(schema Schema, instance interface{}, parentTag *string) e
 			return ErrMaxDepthExceeded
 		}
 
-		refdSchema := vm.RootSchema.Definitions[*schema.Ref]
-		vm.SchemaTokens = append(vm.SchemaTokens, []string{"definitions", *schema.Ref})
+		vm.SchemaTokens = append(vm.SchemaTokens, []string{"definitions", n.refName})
 
-		if err := vm.validate(refdSchema, instance, nil); err != nil {
+		if err := vm.validate(n.ref, instance, nil); err != nil {
 			return err
 		}
 
 		vm.SchemaTokens = vm.SchemaTokens[:len(vm.SchemaTokens)-1]
 	case FormType:
-		switch schema.Type {
+		switch n.typ {
 		case TypeBoolean:
 			if _, ok := instance.(bool); !ok {
 				vm.pushSchemaToken("type")
-				if err := vm.pushErr(); err != nil {
+				if err := vm.pushErr(KindType, n.expected, describe(instance)); err != nil {
 					return err
 				}
 				vm.popSchemaToken()
 			}
-		case TypeNumber, TypeFloat32, TypeFloat64:
-			if _, ok := instance.(float64); !ok {
+		case TypeFloat32:
+			if err := vm.checkFloat32(n, instance); err != nil {
+				return err
+			}
+		case TypeNumber, TypeFloat64:
+			if !isNumber(instance) {
 				vm.pushSchemaToken("type")
-				if err := vm.pushErr(); err != nil {
+				if err := vm.pushErr(KindType, n.expected, describe(instance)); err != nil {
 					return err
 				}
 				vm.popSchemaToken()
 			}
 		case TypeInt8:
-			if err := vm.checkInt(instance, -128.0, 127.0); err != nil {
+			if err := vm.checkInt(n, instance, math.MinInt8, math.MaxInt8); err != nil {
 				return err
 			}
 		case TypeUint8:
-			if err := vm.checkInt(instance, 0.0, 255.0); err != nil {
+			if err := vm.checkInt(n, instance, 0, math.MaxUint8); err != nil {
 				return err
 			}
 		case TypeInt16:
-			if err := vm.checkInt(instance, -32768.0, 32767.0); err != nil {
+			if err := vm.checkInt(n, instance, math.MinInt16, math.MaxInt16); err != nil {
 				return err
 			}
 		case TypeUint16:
-			if err := vm.checkInt(instance, 0.0, 65535.0); err != nil {
+			if err := vm.checkInt(n, instance, 0, math.MaxUint16); err != nil {
 				return err
 			}
 		case TypeInt32:
-			if err := vm.checkInt(instance, -2147483648.0, 2147483647.0); err != nil {
+			if err := vm.checkInt(n, instance, math.MinInt32, math.MaxInt32); err != nil {
 				return err
 			}
 		case TypeUint32:
-			if err := vm.checkInt(instance, 0.0, 4294967295.0); err != nil {
+			if err := vm.checkInt(n, instance, 0, math.MaxUint32); err != nil {
 				return err
 			}
 		case TypeInt64:
-			if err := vm.checkInt(instance, -9223372036854775808.0, 9223372036854775807.0); err != nil {
+			if err := vm.checkInt(n, instance, math.MinInt64, math.MaxInt64); err != nil {
 				return err
 			}
 		case TypeUint64:
-			if err := vm.checkInt(instance, 0.0, 18446744073709551615.0); err != nil {
+			if err := vm.checkInt(n, instance, 0, math.MaxUint64); err != nil {
 				return err
 			}
 		case TypeString:
 			if _, ok := instance.(string); !ok {
 				vm.pushSchemaToken("type")
-				if err := vm.pushErr(); err != nil {
+				if err := vm.pushErr(KindType, n.expected, describe(instance)); err != nil {
 					return err
 				}
 				vm.popSchemaToken()
 			}
 		case TypeTimestamp:
-			if s, ok := instance.(string); ok {
-				if _, err := time.Parse(time.RFC3339, s); err != nil {
-					vm.pushSchemaToken("type")
-					if err := vm.pushErr(); err != nil {
-						return err
-					}
-					vm.popSchemaToken()
-				}
-			} else {
+			if s, ok := instance.(string); !ok || !vm.isTimestamp(s) {
 				vm.pushSchemaToken("type")
-				if err := vm.pushErr(); err != nil {
+				if err := vm.pushErr(KindType, n.expected, describe(instance)); err != nil {
 					return err
 				}
 				vm.popSchemaToken()
@@ -113,23 +129,16 @@ func (vm *vm) validate(schema Schema, instance interface{}, parentTag *string) e
 		}
 	case FormEnum:
 		if s, ok := instance.(string); ok {
-			found := false
-			for _, val := range schema.Enum {
-				if val == s {
-					found = true
-				}
-			}
-
-			if !found {
+			if _, ok := n.enum[s]; !ok {
 				vm.pushSchemaToken("enum")
-				if err := vm.pushErr(); err != nil {
+				if err := vm.pushErr(KindEnum, n.expected, describe(instance)); err != nil {
 					return err
 				}
 				vm.popSchemaToken()
 			}
 		} else {
 			vm.pushSchemaToken("enum")
-			if err := vm.pushErr(); err != nil {
+			if err := vm.pushErr(KindEnum, n.expected, describe(instance)); err != nil {
 				return err
 			}
 			vm.popSchemaToken()
@@ -139,7 +148,7 @@ func (vm *vm) validate(schema Schema, instance interface{}, parentTag *string) e
 			vm.pushSchemaToken("elements")
 			for i, elem := range arr {
 				vm.pushInstanceToken(strconv.Itoa(i))
-				if err := vm.validate(*schema.Elements, elem, nil); err != nil {
+				if err := vm.validate(n.elements, elem, nil); err != nil {
 					return err
 				}
 				vm.popInstanceToken()
@@ -147,76 +156,20 @@ func (vm *vm) validate(schema Schema, instance interface{}, parentTag *string) e
 			vm.popSchemaToken()
 		} else {
 			vm.pushSchemaToken("elements")
-			if err := vm.pushErr(); err != nil {
+			if err := vm.pushErr(KindElements, n.expected, describe(instance)); err != nil {
 				return err
 			}
 			vm.popSchemaToken()
 		}
 	case FormProperties:
 		if obj, ok := instance.(map[string]interface{}); ok {
-			vm.pushSchemaToken("properties")
-			for property, subSchema := range schema.RequiredProperties {
-				vm.pushSchemaToken(property)
-
-				if val, ok := obj[property]; ok {
-					vm.pushInstanceToken(property)
-					if err := vm.validate(subSchema, val, nil); err != nil {
-						return err
-					}
-					vm.popInstanceToken()
-				} else {
-					if err := vm.pushErr(); err != nil {
-						return err
-					}
-				}
-
-				vm.popSchemaToken()
-			}
-			vm.popSchemaToken()
-
-			vm.pushSchemaToken("optionalProperties")
-			for property, subSchema := range schema.OptionalProperties {
-				vm.pushSchemaToken(property)
-
-				if val, ok := obj[property]; ok {
-					vm.pushInstanceToken(property)
-					if err := vm.validate(subSchema, val, nil); err != nil {
-						return err
-					}
-					vm.popInstanceToken()
+			if vm.ErrorOrder == ErrorOrderInstance {
+				if err := vm.validatePropertiesInstanceOrder(n, obj, parentTag); err != nil {
+					return err
 				}
-
-				vm.popSchemaToken()
-			}
-			vm.popSchemaToken()
-
-			if vm.StrictInstanceSemantics {
-				for k := range obj {
-					// Do not apply strict instance semantics rule if the property is the
-					// tag of a parent discriminator. This is the "discriminator tag
-					// exemption" in the spec.
-					if parentTag != nil && k == *parentTag {
-						continue
-					}
-
-					requiredOk := false
-					optionalOk := false
-
-					if schema.RequiredProperties != nil {
-						_, requiredOk = schema.RequiredProperties[k]
-					}
-
-					if schema.OptionalProperties != nil {
-						_, optionalOk = schema.OptionalProperties[k]
-					}
-
-					if !requiredOk && !optionalOk {
-						vm.pushInstanceToken(k)
-						if err := vm.pushErr(); err != nil {
-							return err
-						}
-						vm.popInstanceToken()
-					}
+			} else {
+				if err := vm.validatePropertiesSchemaOrder(n, obj, parentTag); err != nil {
+					return err
 				}
 			}
 		} else {
@@ -224,13 +177,13 @@ func (vm *vm) validate(schema Schema, instance interface{}, parentTag *string) e
 			// instance is an object at all. If it isn't, you produce an error related
 			// to `properties`. But if there wasn't a `properties` keyword, then you
 			// have to produce `optionalProperties` instead.
-			if schema.RequiredProperties != nil {
+			if n.hasRequired {
 				vm.pushSchemaToken("properties")
 			} else {
 				vm.pushSchemaToken("optionalProperties")
 			}
 
-			if err := vm.pushErr(); err != nil {
+			if err := vm.pushErr(KindProperties, n.expected, describe(instance)); err != nil {
 				return err
 			}
 			vm.popSchemaToken()
@@ -238,9 +191,9 @@ func (vm *vm) validate(schema Schema, instance interface{}, parentTag *string) e
 	case FormValues:
 		if obj, ok := instance.(map[string]interface{}); ok {
 			vm.pushSchemaToken("values")
-			for k, v := range obj {
+			for _, k := range objectKeys(obj) {
 				vm.pushInstanceToken(k)
-				if err := vm.validate(*schema.Values, v, nil); err != nil {
+				if err := vm.validate(n.values, obj[k], nil); err != nil {
 					return err
 				}
 				vm.popInstanceToken()
@@ -248,55 +201,54 @@ func (vm *vm) validate(schema Schema, instance interface{}, parentTag *string) e
 			vm.popSchemaToken()
 		} else {
 			vm.pushSchemaToken("values")
-			if err := vm.pushErr(); err != nil {
+			if err := vm.pushErr(KindValues, n.expected, describe(instance)); err != nil {
 				return err
 			}
 			vm.popSchemaToken()
 		}
 	case FormDiscriminator:
 		if obj, ok := instance.(map[string]interface{}); ok {
-			vm.pushSchemaToken("discriminator")
+			if rawTagValue, ok := obj[n.tag]; ok {
+				rawTagValue, err := normalize(rawTagValue)
+				if err != nil {
+					return err
+				}
 
-			if tagValue, ok := obj[schema.Discriminator.Tag]; ok {
-				if tagValue, ok := tagValue.(string); ok {
-					if subSchema, ok := schema.Discriminator.Mapping[tagValue]; ok {
-						vm.pushSchemaToken("mapping")
-						vm.pushSchemaToken(tagValue)
-						if err := vm.validate(subSchema, instance, &schema.Discriminator.Tag); err != nil {
+				if tagValue, ok := rawTagValue.(string); ok {
+					if subSchema, ok := n.mapping[tagValue]; ok {
+						pushed := vm.pushMappingTokens(tagValue)
+						if err := vm.validate(subSchema, instance, &n.tag); err != nil {
 							return err
 						}
-						vm.popSchemaToken()
-						vm.popSchemaToken()
+						vm.popSchemaTokens(pushed)
 					} else {
-						vm.pushSchemaToken("mapping")
-						vm.pushInstanceToken(schema.Discriminator.Tag)
-						if err := vm.pushErr(); err != nil {
+						pushed := vm.pushMappingTokens()
+						vm.pushInstanceToken(n.tag)
+						if err := vm.pushErr(KindUnmappedTag, n.mappingKeys, describe(tagValue)); err != nil {
 							return err
 						}
 						vm.popInstanceToken()
-						vm.popSchemaToken()
+						vm.popSchemaTokens(pushed)
 					}
 				} else {
-					vm.pushSchemaToken("tag")
-					vm.pushInstanceToken(schema.Discriminator.Tag)
-					if err := vm.pushErr(); err != nil {
+					pushed := vm.pushTagTokens()
+					vm.pushInstanceToken(n.tag)
+					if err := vm.pushErr(KindTagType, []string{"string"}, describe(rawTagValue)); err != nil {
 						return err
 					}
 					vm.popInstanceToken()
-					vm.popSchemaToken()
+					vm.popSchemaTokens(pushed)
 				}
 			} else {
-				vm.pushSchemaToken("tag")
-				if err := vm.pushErr(); err != nil {
+				pushed := vm.pushTagTokens()
+				if err := vm.pushErr(KindMissingTag, []string{n.tag}, ""); err != nil {
 					return err
 				}
-				vm.popSchemaToken()
+				vm.popSchemaTokens(pushed)
 			}
-
-			vm.popSchemaToken()
 		} else {
 			vm.pushSchemaToken("discriminator")
-			if err := vm.pushErr(); err != nil {
+			if err := vm.pushErr(KindDiscriminator, n.expected, describe(instance)); err != nil {
 				return err
 			}
 			vm.popSchemaToken()
@@ -306,18 +258,199 @@ func (vm *vm) validate(schema Schema, instance interface{}, parentTag *string) e
 	return nil
 }
 
-func (vm *vm) checkInt(instance interface{}, min, max float64) error {
-	if n, ok := instance.(float64); ok {
-		if i, f := math.Modf(n); f != 0.0 || i < min || i > max {
-			vm.pushSchemaToken("type")
-			if err := vm.pushErr(); err != nil {
+// validatePropertiesSchemaOrder validates an object against a schema of the
+// properties form, visiting required properties, optional properties, and then
+// unknown properties, each sorted by name.
+func (vm *vm) validatePropertiesSchemaOrder(n *node, obj map[string]interface{}, parentTag *string) error {
+	vm.pushSchemaToken("properties")
+	for _, property := range n.requiredNames {
+		vm.pushSchemaToken(property)
+
+		if val, ok := obj[property]; ok {
+			vm.pushInstanceToken(property)
+			if err := vm.validate(n.required[property], val, nil); err != nil {
+				return err
+			}
+			vm.popInstanceToken()
+		} else {
+			if err := vm.pushErr(KindMissingProperty, []string{property}, ""); err != nil {
+				return err
+			}
+		}
+
+		vm.popSchemaToken()
+	}
+	vm.popSchemaToken()
+
+	vm.pushSchemaToken("optionalProperties")
+	for _, property := range n.optionalNames {
+		vm.pushSchemaToken(property)
+
+		if val, ok := obj[property]; ok {
+			vm.pushInstanceToken(property)
+			if err := vm.validate(n.optional[property], val, nil); err != nil {
+				return err
+			}
+			vm.popInstanceToken()
+		}
+
+		vm.popSchemaToken()
+	}
+	vm.popSchemaToken()
+
+	if vm.bansUnknown(n) {
+		var unknown []string
+		for k := range obj {
+			_, requiredOk := n.required[k]
+			_, optionalOk := n.optional[k]
+
+			// Do not apply strict instance semantics rule if the property is the
+			// tag of a parent discriminator. This is the "discriminator tag
+			// exemption" in the spec.
+			if !requiredOk && !optionalOk && (parentTag == nil || k != *parentTag) {
+				unknown = append(unknown, k)
+			}
+		}
+
+		sort.Strings(unknown)
+		for _, k := range unknown {
+			vm.pushInstanceToken(k)
+			if err := vm.pushErr(KindUnknownProperty, n.propertyNames, describe(obj[k])); err != nil {
+				return err
+			}
+			vm.popInstanceToken()
+		}
+	}
+
+	return nil
+}
+
+// validatePropertiesInstanceOrder validates an object against a schema of the
+// properties form, visiting the properties of the object sorted by name, and
+// then reporting missing required properties.
+func (vm *vm) validatePropertiesInstanceOrder(n *node, obj map[string]interface{}, parentTag *string) error {
+	for _, k := range objectKeys(obj) {
+		if subSchema, ok := n.required[k]; ok {
+			vm.pushSchemaToken("properties")
+			vm.pushSchemaToken(k)
+			vm.pushInstanceToken(k)
+			if err := vm.validate(subSchema, obj[k], nil); err != nil {
+				return err
+			}
+			vm.popInstanceToken()
+			vm.popSchemaToken()
+			vm.popSchemaToken()
+		} else if subSchema, ok := n.optional[k]; ok {
+			vm.pushSchemaToken("optionalProperties")
+			vm.pushSchemaToken(k)
+			vm.pushInstanceToken(k)
+			if err := vm.validate(subSchema, obj[k], nil); err != nil {
 				return err
 			}
+			vm.popInstanceToken()
+			vm.popSchemaToken()
 			vm.popSchemaToken()
+		} else if vm.bansUnknown(n) && (parentTag == nil || k != *parentTag) {
+			// See validatePropertiesSchemaOrder on the discriminator tag exemption.
+			vm.pushInstanceToken(k)
+			if err := vm.pushErr(KindUnknownProperty, n.propertyNames, describe(obj[k])); err != nil {
+				return err
+			}
+			vm.popInstanceToken()
 		}
+	}
+
+	vm.pushSchemaToken("properties")
+	for _, property := range n.requiredNames {
+		if _, ok := obj[property]; !ok {
+			vm.pushSchemaToken(property)
+			if err := vm.pushErr(KindMissingProperty, []string{property}, ""); err != nil {
+				return err
+			}
+			vm.popSchemaToken()
+		}
+	}
+	vm.popSchemaToken()
+
+	return nil
+}
+
+// bansUnknown returns whether properties not in a schema of the properties form
+// are reported as unknown.
+func (vm *vm) bansUnknown(n *node) bool {
+	return (vm.StrictInstanceSemantics || vm.RFC8927) && !n.additional
+}
+
+// pushMappingTokens pushes the schema tokens of the mapping of a schema of the
+// discriminator form, followed by tokens, and returns how many tokens were
+// pushed. The JSL spec nests "mapping" within "discriminator", but RFC 8927
+// does not.
+func (vm *vm) pushMappingTokens(tokens ...string) int {
+	if vm.RFC8927 {
+		tokens = append([]string{"mapping"}, tokens...)
 	} else {
+		tokens = append([]string{"discriminator", "mapping"}, tokens...)
+	}
+
+	for _, token := range tokens {
+		vm.pushSchemaToken(token)
+	}
+
+	return len(tokens)
+}
+
+// pushTagTokens pushes the schema tokens for errors about the tag of a schema of
+// the discriminator form, and returns how many tokens were pushed. RFC 8927
+// reports such errors at "discriminator", which is the tag itself.
+func (vm *vm) pushTagTokens() int {
+	vm.pushSchemaToken("discriminator")
+	if vm.RFC8927 {
+		return 1
+	}
+
+	vm.pushSchemaToken("tag")
+	return 2
+}
+
+// isTimestamp returns whether s is a RFC 3339 timestamp. Under RFC 8927, a
+// leap second is also accepted, which time.Parse would otherwise reject.
+func (vm *vm) isTimestamp(s string) bool {
+	if _, err := time.Parse(time.RFC3339, s); err == nil {
+		return true
+	}
+
+	if vm.RFC8927 && len(s) > 19 && s[16:19] == ":60" {
+		_, err := time.Parse(time.RFC3339, s[:16]+":59"+s[19:])
+		return err == nil
+	}
+
+	return false
+}
+
+// checkInt checks that instance is an integer in the range [min, max].
+//
+// The check is exact for int64, uint64 and json.Number instances. For float64
+// instances, min and max are converted to float64 as well, which rounds the
+// bounds of int64 and uint64 up to 2^63 and 2^64. Those are accepted, since
+// they are the float64 nearest to the largest int64 and uint64.
+func (vm *vm) checkInt(n *node, instance interface{}, min int64, max uint64) error {
+	ok := false
+
+	switch num := instance.(type) {
+	case float64:
+		i, f := math.Modf(num)
+		ok = f == 0.0 && i >= float64(min) && i <= float64(max)
+	case json.Number:
+		ok = checkNumberInt(num, min, max)
+	case int64:
+		ok = num >= min && (num < 0 || uint64(num) <= max)
+	case uint64:
+		ok = num <= max
+	}
+
+	if !ok {
 		vm.pushSchemaToken("type")
-		if err := vm.pushErr(); err != nil {
+		if err := vm.pushErr(KindType, n.expected, describe(instance)); err != nil {
 			return err
 		}
 		vm.popSchemaToken()
@@ -326,6 +459,75 @@ func (vm *vm) checkInt(instance interface{}, min, max float64) error {
 	return nil
 }
 
+// checkNumberInt is checkInt for json.Number, using arbitrary precision where
+// the number does not fit in an int64 or uint64 as-is.
+func checkNumberInt(n json.Number, min int64, max uint64) bool {
+	if i, err := strconv.ParseInt(string(n), 10, 64); err == nil {
+		return i >= min && (i < 0 || uint64(i) <= max)
+	}
+
+	if u, err := strconv.ParseUint(string(n), 10, 64); err == nil {
+		return u <= max
+	}
+
+	// Numbers like 1e3 or 1.0 are integers too, and integers that are too large
+	// for either an int64 or a uint64 end up here as well.
+	r, ok := new(big.Rat).SetString(string(n))
+	if !ok || !r.IsInt() {
+		return false
+	}
+
+	return r.Num().Cmp(new(big.Int).SetInt64(min)) >= 0 && r.Num().Cmp(new(big.Int).SetUint64(max)) <= 0
+}
+
+// checkFloat32 checks that instance is a number and, depending on
+// StrictFloat32 and ExactFloat32, that it is suitable as a float32.
+func (vm *vm) checkFloat32(n *node, instance interface{}) error {
+	kind := Kind(0)
+
+	if !isNumber(instance) {
+		kind = KindType
+	} else if vm.StrictFloat32 || vm.ExactFloat32 {
+		f, exact := toFloat32(instance)
+		if math.IsInf(float64(f), 0) {
+			kind = KindFloat32Range
+		} else if vm.ExactFloat32 && !exact {
+			kind = KindFloat32Precision
+		}
+	}
+
+	if kind != 0 {
+		vm.pushSchemaToken("type")
+		if err := vm.pushErr(kind, n.expected, describe(instance)); err != nil {
+			return err
+		}
+		vm.popSchemaToken()
+	}
+
+	return nil
+}
+
+// toFloat32 rounds a numeric instance to the nearest float32, and reports
+// whether it was exactly representable. Numbers too large for a float32 are
+// rounded to an infinity.
+func toFloat32(instance interface{}) (float32, bool) {
+	switch n := instance.(type) {
+	case float64:
+		f := float32(n)
+		return f, float64(f) == n
+	case json.Number:
+		if r, ok := new(big.Rat).SetString(string(n)); ok {
+			return r.Float32()
+		}
+	case int64:
+		return new(big.Rat).SetInt64(n).Float32()
+	case uint64:
+		return new(big.Rat).SetInt(new(big.Int).SetUint64(n)).Float32()
+	}
+
+	return float32(math.NaN()), false
+}
+
 func (vm *vm) pushInstanceToken(token string) {
 	vm.InstanceTokens = append(vm.InstanceTokens, token)
 }
@@ -343,7 +545,12 @@ func (vm *vm) popSchemaToken() {
 	vm.SchemaTokens[len(vm.SchemaTokens)-1] = schemaTokens[:len(schemaTokens)-1]
 }
 
-func (vm *vm) pushErr() error {
+func (vm *vm) popSchemaTokens(n int) {
+	schemaTokens := vm.SchemaTokens[len(vm.SchemaTokens)-1]
+	vm.SchemaTokens[len(vm.SchemaTokens)-1] = schemaTokens[:len(schemaTokens)-n]
+}
+
+func (vm *vm) pushErr(kind Kind, expected []string, actual string) error {
 	instanceTokens := make([]string, len(vm.InstanceTokens))
 	copy(instanceTokens, vm.InstanceTokens)
 
@@ -353,9 +560,12 @@ func (vm *vm) pushErr() error {
 	vm.Errors = append(vm.Errors, ValidationError{
 		InstancePath: instanceTokens,
 		SchemaPath:   schemaTokens,
+		Kind:         kind,
+		Expected:     append([]string(nil), expected...),
+		Actual:       actual,
 	})
 
-	if len(vm.Errors) == vm.MaxErrors {
+	if vm.buffering == 0 && len(vm.Errors) == vm.MaxErrors {
 		return errMaxErrors
 	}
 